@@ -0,0 +1,41 @@
+// Package audit 追加审计事件，供请求处理和后台任务共用
+package audit
+
+import (
+	"log"
+
+	"tally/models"
+
+	"gorm.io/gorm"
+)
+
+// Actor 审计事件的操作者，后台任务为零值（ActorID 为 0）
+type Actor struct {
+	ID        uint
+	Name      string
+	IP        string
+	UserAgent string
+}
+
+// Record 在 db（可以是事务）中追加一条审计事件，before 和 after 为操作前后的对象，创建时 before 为 nil，删除时 after 为 nil
+//
+// 比较变更失败时仍记录事件本身，只是不含变更字段
+func Record(db *gorm.DB, actor Actor, action, targetType string, targetID, workspaceID uint, before, after interface{}) error {
+	changes, err := models.DiffAudit(before, after)
+	if err != nil {
+		log.Printf("Failed to diff audit event %s: %v", action, err)
+	}
+
+	event := models.AuditEvent{
+		ActorID:     actor.ID,
+		ActorName:   actor.Name,
+		Action:      action,
+		TargetType:  targetType,
+		TargetID:    targetID,
+		WorkspaceID: workspaceID,
+		Changes:     changes,
+		IP:          actor.IP,
+		UserAgent:   actor.UserAgent,
+	}
+	return db.Create(&event).Error
+}
@@ -1,16 +1,63 @@
 package config
 
-import "os"
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"log"
+	"net"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	DefaultPort     = "8080"
+	DefaultUsername = "admin"
+	DefaultPassword = "password" // 旧版本初始化的默认密码，仍在使用时要求修改
+	DatabasePath    = "./data.db"
+)
+
+// 登录会话：access token 短期有效，过期后用刷新令牌换取新的 token
+const (
+	AccessTokenExpire  = 15 * time.Minute
+	RefreshTokenExpire = 30 * 24 * time.Hour // 超过该时间未刷新则需要重新登录
+)
 
+// 登录限流：同一 IP 在时间窗口内失败次数达到上限后拒绝登录，失败后需等待的时间随次数翻倍
 const (
-	DefaultPort      = "8080"
-	JWTSecret        = "tally-secret-key-change-in-production"
-	JWTExpireHours   = 24 * 7 // 7 天
-	DefaultUsername  = "admin"
-	DefaultPassword  = "password"
-	DatabasePath     = "./data.db"
+	LoginFailureWindow = 15 * time.Minute
+	LoginIPMaxFailures = 20
+	LoginDelayMax      = 30 * time.Second
 )
 
+// DefaultLoginMaxFailures 默认连续失败多少次后锁定账户
+const DefaultLoginMaxFailures = 5
+
+// DefaultLoginLockoutDuration 默认账户锁定时长
+const DefaultLoginLockoutDuration = 15 * time.Minute
+
+// TwoFactorChallengeExpire 两步验证挑战 token 的有效期
+const TwoFactorChallengeExpire = 5 * time.Minute
+
+// TOTPIssuer 验证器应用中显示的签发方名称
+const TOTPIssuer = "Tally"
+
+// DefaultAuditRetentionDays 默认审计事件保留天数
+const DefaultAuditRetentionDays = 365
+
+// DefaultBaseCurrency 默认基准货币，支出报表换算为该货币
+const DefaultBaseCurrency = "USD"
+
+// DefaultNotifyInterval 默认到期扫描间隔
+const DefaultNotifyInterval = time.Hour
+
+// DefaultReminderDays 默认提醒阈值（距离到期的天数）
+var DefaultReminderDays = []int{30, 7, 3, 1, 0}
+
 func GetPort() string {
 	if port := os.Getenv("PORT"); port != "" {
 		return port
@@ -18,9 +65,246 @@ func GetPort() string {
 	return DefaultPort
 }
 
+// JWTSecretFile 首次启动时生成的 JWT 密钥，保存在数据目录中
+const JWTSecretFile = "jwt_secret"
+
+var (
+	jwtSecret     string
+	jwtSecretOnce sync.Once
+)
+
+// GetJWTSecret 优先使用 JWT_SECRET 环境变量，否则读取数据目录中的密钥文件，不存在时随机生成并保存
 func GetJWTSecret() string {
-	if secret := os.Getenv("JWT_SECRET"); secret != "" {
-		return secret
+	jwtSecretOnce.Do(func() {
+		if secret := os.Getenv("JWT_SECRET"); secret != "" {
+			jwtSecret = secret
+			return
+		}
+
+		path := filepath.Join(filepath.Dir(DatabasePath), JWTSecretFile)
+		if b, err := os.ReadFile(path); err == nil && len(strings.TrimSpace(string(b))) > 0 {
+			jwtSecret = strings.TrimSpace(string(b))
+			return
+		}
+
+		b := make([]byte, 32)
+		if _, err := rand.Read(b); err != nil {
+			log.Fatal("Failed to generate JWT secret:", err)
+		}
+		jwtSecret = hex.EncodeToString(b)
+		if err := os.WriteFile(path, []byte(jwtSecret+"\n"), 0600); err != nil {
+			log.Fatal("Failed to save JWT secret:", err)
+		}
+		log.Printf("Generated JWT secret: %s", path)
+	})
+	return jwtSecret
+}
+
+// GetNotifyInterval 到期扫描间隔，NOTIFY_INTERVAL 使用 Go duration 格式（如 30m、1h）
+func GetNotifyInterval() time.Duration {
+	if v := os.Getenv("NOTIFY_INTERVAL"); v != "" {
+		if d, err := time.ParseDuration(v); err == nil && d > 0 {
+			return d
+		}
+	}
+	return DefaultNotifyInterval
+}
+
+// GetReminderDays 全局提醒阈值，REMINDER_DAYS 为逗号分隔的天数（如 30,7,1），降序返回
+func GetReminderDays() []int {
+	days := DefaultReminderDays
+	if v := os.Getenv("REMINDER_DAYS"); v != "" {
+		var parsed []int
+		for _, s := range strings.Split(v, ",") {
+			if n, err := strconv.Atoi(strings.TrimSpace(s)); err == nil && n >= 0 {
+				parsed = append(parsed, n)
+			}
+		}
+		if len(parsed) > 0 {
+			days = parsed
+		}
+	}
+
+	result := append([]int(nil), days...)
+	sort.Sort(sort.Reverse(sort.IntSlice(result)))
+	return result
+}
+
+// GetReminderRepeatDays 全局过期后重复提醒间隔（天），REMINDER_REPEAT_DAYS 为 0 或未设置时不重复
+func GetReminderRepeatDays() int {
+	if v := os.Getenv("REMINDER_REPEAT_DAYS"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n > 0 {
+			return n
+		}
+	}
+	return 0
+}
+
+// GetNotifyAllowPrivateTargets NOTIFY_ALLOW_PRIVATE_TARGETS=true 时普通用户的通知渠道也可以访问内网地址
+func GetNotifyAllowPrivateTargets() bool {
+	return os.Getenv("NOTIFY_ALLOW_PRIVATE_TARGETS") == "true"
+}
+
+// OIDCConfig OpenID Connect 单点登录配置
+type OIDCConfig struct {
+	Issuer        string
+	ClientID      string
+	ClientSecret  string
+	RedirectURL   string // 需在身份提供方登记，指向 /api/auth/oidc/callback
+	Scopes        []string
+	ProviderName  string // 登录页按钮上显示的名称
+	UsernameClaim string
+	AdminClaim    string // 映射管理员角色的声明，如 groups
+	AdminValue    string // 声明中包含该值时授予管理员角色，为空时不同步角色
+}
+
+// Enabled 是否已配置 OIDC 登录
+func (c OIDCConfig) Enabled() bool {
+	return c.Issuer != "" && c.ClientID != "" && c.RedirectURL != ""
+}
+
+// GetOIDCConfig 从 OIDC_* 环境变量读取单点登录配置
+func GetOIDCConfig() OIDCConfig {
+	cfg := OIDCConfig{
+		Issuer:        os.Getenv("OIDC_ISSUER"),
+		ClientID:      os.Getenv("OIDC_CLIENT_ID"),
+		ClientSecret:  os.Getenv("OIDC_CLIENT_SECRET"),
+		RedirectURL:   os.Getenv("OIDC_REDIRECT_URL"),
+		Scopes:        []string{"openid", "profile", "email"},
+		ProviderName:  "SSO",
+		UsernameClaim: "preferred_username",
+		AdminClaim:    "groups",
+		AdminValue:    os.Getenv("OIDC_ADMIN_VALUE"),
+	}
+	if v := os.Getenv("OIDC_SCOPES"); v != "" {
+		cfg.Scopes = strings.Fields(strings.ReplaceAll(v, ",", " "))
+	}
+	if v := os.Getenv("OIDC_PROVIDER_NAME"); v != "" {
+		cfg.ProviderName = v
+	}
+	if v := os.Getenv("OIDC_USERNAME_CLAIM"); v != "" {
+		cfg.UsernameClaim = v
+	}
+	if v := os.Getenv("OIDC_ADMIN_CLAIM"); v != "" {
+		cfg.AdminClaim = v
+	}
+	return cfg
+}
+
+// ProxyAuthConfig 反向代理请求头认证配置
+type ProxyAuthConfig struct {
+	Header         string       // 代理传递用户名的请求头，如 Remote-User
+	TrustedProxies []*net.IPNet // 只信任来自这些地址的请求头
+	AutoCreate     bool         // 用户不存在时自动创建
+}
+
+// Enabled 是否已启用反向代理认证，需同时配置请求头和可信代理
+func (c ProxyAuthConfig) Enabled() bool {
+	return c.Header != "" && len(c.TrustedProxies) > 0
+}
+
+// Trusted 判断请求的直接来源地址是否为可信代理
+func (c ProxyAuthConfig) Trusted(ip net.IP) bool {
+	for _, n := range c.TrustedProxies {
+		if n.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// GetProxyAuthConfig 读取 AUTH_PROXY_HEADER、AUTH_PROXY_TRUSTED（逗号分隔的 CIDR 或 IP）和 AUTH_PROXY_AUTO_CREATE
+func GetProxyAuthConfig() ProxyAuthConfig {
+	return ProxyAuthConfig{
+		Header:         strings.TrimSpace(os.Getenv("AUTH_PROXY_HEADER")),
+		TrustedProxies: parseNetworks("AUTH_PROXY_TRUSTED"),
+		AutoCreate:     os.Getenv("AUTH_PROXY_AUTO_CREATE") != "false",
+	}
+}
+
+// GetTrustedProxies 允许通过 X-Forwarded-For 传递客户端地址的代理，读取 TRUSTED_PROXIES，未设置时使用 AUTH_PROXY_TRUSTED
+//
+// 都未设置时返回 nil，不信任任何 X-Forwarded-For，客户端地址即 TCP 连接地址
+func GetTrustedProxies() []string {
+	networks := parseNetworks("TRUSTED_PROXIES")
+	if networks == nil {
+		networks = parseNetworks("AUTH_PROXY_TRUSTED")
+	}
+	var proxies []string
+	for _, n := range networks {
+		proxies = append(proxies, n.String())
+	}
+	return proxies
+}
+
+// parseNetworks 解析环境变量中逗号分隔的 CIDR 或 IP，无效的条目忽略
+func parseNetworks(key string) []*net.IPNet {
+	var networks []*net.IPNet
+	for _, s := range strings.Split(os.Getenv(key), ",") {
+		s = strings.TrimSpace(s)
+		if s == "" {
+			continue
+		}
+		if !strings.Contains(s, "/") {
+			if ip := net.ParseIP(s); ip != nil && ip.To4() != nil {
+				s += "/32"
+			} else {
+				s += "/128"
+			}
+		}
+		_, n, err := net.ParseCIDR(s)
+		if err != nil {
+			log.Printf("Warning: Invalid %s entry %q ignored", key, s)
+			continue
+		}
+		networks = append(networks, n)
+	}
+	return networks
+}
+
+// GetLoginMaxFailures 连续登录失败多少次后锁定账户，LOGIN_MAX_FAILURES 为 0 时不锁定
+func GetLoginMaxFailures() int {
+	if v := os.Getenv("LOGIN_MAX_FAILURES"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n >= 0 {
+			return n
+		}
+	}
+	return DefaultLoginMaxFailures
+}
+
+// GetLoginLockoutDuration 账户锁定时长，LOGIN_LOCKOUT_DURATION 使用 Go duration 格式（如 30m）
+func GetLoginLockoutDuration() time.Duration {
+	if v := os.Getenv("LOGIN_LOCKOUT_DURATION"); v != "" {
+		if d, err := time.ParseDuration(v); err == nil && d > 0 {
+			return d
+		}
+	}
+	return DefaultLoginLockoutDuration
+}
+
+// GetAuditRetentionDays 审计事件保留天数，AUDIT_RETENTION_DAYS 为 0 时永久保留
+func GetAuditRetentionDays() int {
+	if v := os.Getenv("AUDIT_RETENTION_DAYS"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n >= 0 {
+			return n
+		}
+	}
+	return DefaultAuditRetentionDays
+}
+
+// GetBaseCurrency 基准货币，BASE_CURRENCY 为 ISO 4217 代码，汇率表中的汇率均相对于该货币
+func GetBaseCurrency() string {
+	if v := strings.ToUpper(strings.TrimSpace(os.Getenv("BASE_CURRENCY"))); len(v) == 3 {
+		return v
+	}
+	return DefaultBaseCurrency
+}
+
+// GetInitialAdmin 首次启动时创建的管理员账号，ADMIN_PASSWORD 为空时随机生成密码
+func GetInitialAdmin() (username, password string) {
+	username = os.Getenv("ADMIN_USERNAME")
+	if username == "" {
+		username = DefaultUsername
 	}
-	return JWTSecret
+	return username, os.Getenv("ADMIN_PASSWORD")
 }
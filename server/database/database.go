@@ -1,7 +1,9 @@
 package database
 
 import (
+	"crypto/rand"
 	"log"
+	"math/big"
 
 	"tally/config"
 	"tally/models"
@@ -21,20 +23,63 @@ func InitDB() {
 	}
 
 	// 自动迁移
-	if err := DB.AutoMigrate(&models.User{}, &models.Resource{}); err != nil {
+	if err := DB.AutoMigrate(
+		&models.User{},
+		&models.APIToken{},
+		&models.RecoveryCode{},
+		&models.Session{},
+		&models.LoginAttempt{},
+		&models.Workspace{},
+		&models.WorkspaceMember{},
+		&models.Resource{},
+		&models.ReminderAck{},
+		&models.EscalationPolicy{},
+		&models.EscalationState{},
+		&models.NotificationChannel{},
+		&models.NotificationLog{},
+		&models.WebhookDelivery{},
+		&models.DigestSetting{},
+		&models.DigestHistory{},
+		&models.AuditEvent{},
+		&models.Renewal{},
+		&models.ExchangeRate{},
+	); err != nil {
+		log.Fatal("Failed to migrate database:", err)
+	}
+	if err := DB.Exec("CREATE UNIQUE INDEX IF NOT EXISTS idx_users_oidc_subject ON users(oidc_subject)").Error; err != nil {
 		log.Fatal("Failed to migrate database:", err)
 	}
 
 	// 初始化默认用户
 	initDefaultUser()
+	migrateDefaultPassword()
+
+	// 为升级前没有所有者的数据指定所有者
+	migrateOwnership()
+
+	// 保证至少存在一个管理员
+	migrateAdminRole()
+
+	// 为每个用户创建个人工作区，并将旧数据归入所有者的个人工作区
+	migrateWorkspaces()
+	migrateGroupSettings()
 }
 
 func initDefaultUser() {
 	var count int64
 	DB.Model(&models.User{}).Count(&count)
 	if count == 0 {
+		username, password := config.GetInitialAdmin()
+		generated := password == ""
+		if generated {
+			var err error
+			if password, err = generatePassword(); err != nil {
+				log.Fatal("Failed to generate password:", err)
+			}
+		}
+
 		hashedPassword, err := bcrypt.GenerateFromPassword(
-			[]byte(config.DefaultPassword),
+			[]byte(password),
 			bcrypt.DefaultCost,
 		)
 		if err != nil {
@@ -42,12 +87,131 @@ func initDefaultUser() {
 		}
 
 		user := models.User{
-			Username: config.DefaultUsername,
-			Password: string(hashedPassword),
+			Username:           username,
+			Password:           string(hashedPassword),
+			Role:               models.RoleAdmin,
+			MustChangePassword: true,
 		}
 		if err := DB.Create(&user).Error; err != nil {
 			log.Fatal("Failed to create default user:", err)
 		}
-		log.Printf("Created default user: %s", config.DefaultUsername)
+		log.Printf("Created default user: %s", username)
+		// 随机密码只在此输出一次
+		if generated {
+			log.Printf("Initial password: %s (must be changed after first login)", password)
+		}
+	}
+}
+
+// generatePassword 生成 16 位随机初始密码，不含易混淆的字符
+func generatePassword() (string, error) {
+	const alphabet = "abcdefghijkmnpqrstuvwxyzABCDEFGHJKLMNPQRSTUVWXYZ23456789"
+	b := make([]byte, 16)
+	for i := range b {
+		n, err := rand.Int(rand.Reader, big.NewInt(int64(len(alphabet))))
+		if err != nil {
+			return "", err
+		}
+		b[i] = alphabet[n.Int64()]
+	}
+	return string(b), nil
+}
+
+// migrateDefaultPassword 旧版本初始化的 admin / password 账号仍在使用默认密码时，要求修改密码
+func migrateDefaultPassword() {
+	var user models.User
+	if DB.Where("username = ? AND must_change_password = ?", config.DefaultUsername, false).Limit(1).Find(&user).RowsAffected == 0 {
+		return
+	}
+	if bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(config.DefaultPassword)) != nil {
+		return
+	}
+	if err := DB.Model(&user).Update("must_change_password", true).Error; err != nil {
+		log.Fatal("Failed to flag default password:", err)
+	}
+	log.Printf("Warning: User %q still uses the default password and must change it", user.Username)
+}
+
+// migrateOwnership 将没有所有者的资源归属到第一个用户（初始管理员）
+func migrateOwnership() {
+	var admin models.User
+	if err := DB.Order("id").First(&admin).Error; err != nil {
+		log.Fatal("Failed to find initial user:", err)
+	}
+
+	result := DB.Model(&models.Resource{}).Where("user_id = 0").Update("user_id", admin.ID)
+	if result.Error != nil {
+		log.Fatal("Failed to migrate resource owners:", result.Error)
+	}
+	if result.RowsAffected > 0 {
+		log.Printf("Assigned %d resources without owner to user: %s", result.RowsAffected, admin.Username)
+	}
+}
+
+// migrateAdminRole 没有管理员时将第一个用户设为管理员（从无角色的旧版本升级）
+func migrateAdminRole() {
+	var count int64
+	DB.Model(&models.User{}).Where("role = ?", models.RoleAdmin).Count(&count)
+	if count > 0 {
+		return
+	}
+
+	var admin models.User
+	if err := DB.Order("id").First(&admin).Error; err != nil {
+		log.Fatal("Failed to find initial user:", err)
+	}
+	if err := DB.Model(&admin).Updates(map[string]interface{}{"role": models.RoleAdmin, "disabled": false}).Error; err != nil {
+		log.Fatal("Failed to migrate admin role:", err)
+	}
+	log.Printf("Granted admin role to user: %s", admin.Username)
+}
+
+// migrateWorkspaces 为没有个人工作区的用户补建，并将未归属工作区的资源归入创建者的个人工作区
+func migrateWorkspaces() {
+	var users []models.User
+	if err := DB.Order("id").Find(&users).Error; err != nil {
+		log.Fatal("Failed to load users:", err)
+	}
+	for i := range users {
+		if _, err := models.CreatePersonalWorkspace(DB, &users[i]); err != nil {
+			log.Fatal("Failed to create personal workspace:", err)
+		}
+	}
+
+	personal := DB.Model(&models.Workspace{}).Select("id").Where("owner_id = resources.user_id")
+	if err := DB.Model(&models.Resource{}).Where("workspace_id = 0").
+		Update("workspace_id", personal).Error; err != nil {
+		log.Fatal("Failed to migrate resource workspaces:", err)
+	}
+}
+
+// migrateGroupSettings 分组设置从全局唯一、按用户区分改为按工作区区分
+// 需先补齐并填充 workspace_id 再创建新的唯一索引，因此不放在统一的 AutoMigrate 中
+func migrateGroupSettings() {
+	migrator := DB.Migrator()
+	if migrator.HasTable(&models.GroupSetting{}) && !migrator.HasColumn(&models.GroupSetting{}, "workspace_id") {
+		for _, index := range []string{"idx_group_settings_name", "idx_group_setting_user_name"} {
+			if migrator.HasIndex(&models.GroupSetting{}, index) {
+				if err := migrator.DropIndex(&models.GroupSetting{}, index); err != nil {
+					log.Fatal("Failed to drop legacy group setting index:", err)
+				}
+			}
+		}
+		if err := migrator.AddColumn(&models.GroupSetting{}, "WorkspaceID"); err != nil {
+			log.Fatal("Failed to add group setting workspace column:", err)
+		}
+
+		// 按原所有者归入其个人工作区，没有所有者的归入初始管理员的个人工作区
+		owner := "(SELECT id FROM users ORDER BY id LIMIT 1)"
+		if migrator.HasColumn(&models.GroupSetting{}, "user_id") {
+			owner = "CASE WHEN user_id != 0 THEN user_id ELSE " + owner + " END"
+		}
+		if err := DB.Exec("UPDATE group_settings SET workspace_id = (SELECT id FROM workspaces WHERE owner_id = " + owner + ")").Error; err != nil {
+			log.Fatal("Failed to migrate group setting workspaces:", err)
+		}
+	}
+
+	if err := DB.AutoMigrate(&models.GroupSetting{}); err != nil {
+		log.Fatal("Failed to migrate database:", err)
 	}
 }
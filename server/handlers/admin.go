@@ -0,0 +1,296 @@
+package handlers
+
+import (
+	"net/http"
+
+	"tally/database"
+	"tally/models"
+
+	"github.com/gin-gonic/gin"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+)
+
+type CreateUserRequest struct {
+	Username string `json:"username" binding:"required"`
+	Password string `json:"password" binding:"required,min=6"`
+	Role     string `json:"role"` // 默认为 user
+}
+
+type UpdateUserRequest struct {
+	Role     *string `json:"role"`
+	Disabled *bool   `json:"disabled"`
+}
+
+type ResetPasswordRequest struct {
+	NewPassword string `json:"new_password" binding:"required,min=6"`
+}
+
+// findUser 按路径参数获取用户
+func findUser(c *gin.Context, user *models.User) bool {
+	if err := database.DB.First(user, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return false
+	}
+	return true
+}
+
+// isLastAdmin 判断用户是否为唯一可用的管理员
+func isLastAdmin(user *models.User) bool {
+	if !user.IsAdmin() || user.Disabled {
+		return false
+	}
+	var count int64
+	database.DB.Model(&models.User{}).
+		Where("role = ? AND disabled = ? AND id != ?", models.RoleAdmin, false, user.ID).
+		Count(&count)
+	return count == 0
+}
+
+// ListUsers 获取所有用户
+func ListUsers(c *gin.Context) {
+	var users []models.User
+	if err := database.DB.Order("id").Find(&users).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch users"})
+		return
+	}
+
+	c.JSON(http.StatusOK, users)
+}
+
+// CreateUser 创建用户
+func CreateUser(c *gin.Context) {
+	var req CreateUserRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request, password must be at least 6 characters"})
+		return
+	}
+	if req.Role == "" {
+		req.Role = models.RoleUser
+	}
+	if !models.IsValidRole(req.Role) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Role must be 'admin' or 'user'"})
+		return
+	}
+
+	// 检查用户名是否已存在
+	var existingUser models.User
+	if err := database.DB.Where("username = ?", req.Username).First(&existingUser).Error; err == nil {
+		c.JSON(http.StatusConflict, gin.H{"error": "Username already exists"})
+		return
+	}
+
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to hash password"})
+		return
+	}
+
+	user := models.User{
+		Username: req.Username,
+		Password: string(hashedPassword),
+		Role:     req.Role,
+	}
+	if err := database.DB.Create(&user).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create user"})
+		return
+	}
+
+	recordAudit(c, models.AuditUserCreate, models.AuditTargetUser, user.ID, 0, nil, user)
+	c.JSON(http.StatusCreated, user)
+}
+
+// UpdateUser 修改用户角色或禁用状态
+func UpdateUser(c *gin.Context) {
+	userID := uint(c.MustGet("user_id").(float64))
+
+	var req UpdateUserRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request"})
+		return
+	}
+	if req.Role != nil && !models.IsValidRole(*req.Role) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Role must be 'admin' or 'user'"})
+		return
+	}
+
+	var user models.User
+	if !findUser(c, &user) {
+		return
+	}
+
+	demote := req.Role != nil && *req.Role != models.RoleAdmin
+	disable := req.Disabled != nil && *req.Disabled
+	if (demote || disable) && user.ID == userID {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Cannot disable or demote yourself"})
+		return
+	}
+	if (demote || disable) && isLastAdmin(&user) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Cannot disable or demote the last admin"})
+		return
+	}
+
+	before := user
+	if req.Role != nil {
+		user.Role = *req.Role
+	}
+	if req.Disabled != nil {
+		user.Disabled = *req.Disabled
+	}
+
+	if err := database.DB.Save(&user).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update user"})
+		return
+	}
+	// 禁用的用户需要重新登录
+	if disable {
+		revokeSessions(database.DB, user.ID, 0)
+	}
+
+	recordAudit(c, models.AuditUserUpdate, models.AuditTargetUser, user.ID, 0, before, user)
+	c.JSON(http.StatusOK, user)
+}
+
+// ResetUserPassword 重置用户密码，无需旧密码
+func ResetUserPassword(c *gin.Context) {
+	var req ResetPasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request, password must be at least 6 characters"})
+		return
+	}
+
+	var user models.User
+	if !findUser(c, &user) {
+		return
+	}
+
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(req.NewPassword), bcrypt.DefaultCost)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to hash password"})
+		return
+	}
+
+	// 重置密码后该用户的所有会话和 API Token 失效
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&user).Update("password", string(hashedPassword)).Error; err != nil {
+			return err
+		}
+		if err := revokeSessions(tx, user.ID, 0); err != nil {
+			return err
+		}
+		return revokeAPITokens(tx, user.ID)
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to reset password"})
+		return
+	}
+
+	// 密码哈希不输出到 JSON，审计事件中只记录操作本身
+	recordAudit(c, models.AuditUserResetPassword, models.AuditTargetUser, user.ID, 0, user, user)
+	c.JSON(http.StatusOK, gin.H{"message": "Password reset successfully"})
+}
+
+// DeleteUser 删除用户及其所有数据
+func DeleteUser(c *gin.Context) {
+	userID := uint(c.MustGet("user_id").(float64))
+
+	var user models.User
+	if !findUser(c, &user) {
+		return
+	}
+	if user.ID == userID {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Cannot delete yourself"})
+		return
+	}
+	if isLastAdmin(&user) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Cannot delete the last admin"})
+		return
+	}
+
+	if err := database.DB.Transaction(func(tx *gorm.DB) error {
+		return deleteUserData(tx, user.ID)
+	}); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete user"})
+		return
+	}
+
+	recordAudit(c, models.AuditUserDelete, models.AuditTargetUser, user.ID, 0, user, nil)
+	c.JSON(http.StatusOK, gin.H{"message": "User deleted"})
+}
+
+// deleteUserData 删除用户及其个人工作区、通知渠道、设置等关联数据，并退出共享工作区
+func deleteUserData(tx *gorm.DB, userID uint) error {
+	var memberships []models.WorkspaceMember
+	if err := tx.Where("user_id = ?", userID).Find(&memberships).Error; err != nil {
+		return err
+	}
+	if err := tx.Where("user_id = ?", userID).Delete(&models.WorkspaceMember{}).Error; err != nil {
+		return err
+	}
+
+	for _, m := range memberships {
+		var workspace models.Workspace
+		if err := tx.First(&workspace, m.WorkspaceID).Error; err != nil {
+			continue
+		}
+
+		var remaining []models.WorkspaceMember
+		if err := tx.Where("workspace_id = ?", workspace.ID).Order("id").Find(&remaining).Error; err != nil {
+			return err
+		}
+		// 个人工作区以及没有其他成员的共享工作区随用户一起删除
+		if workspace.Personal() || len(remaining) == 0 {
+			if err := deleteWorkspaceData(tx, workspace.ID); err != nil {
+				return err
+			}
+			continue
+		}
+		// 共享工作区失去唯一管理员时，由最早加入的成员接任
+		if m.Role == models.WorkspaceAdmin && !hasWorkspaceAdmin(remaining) {
+			if err := tx.Model(&remaining[0]).Update("role", models.WorkspaceAdmin).Error; err != nil {
+				return err
+			}
+		}
+	}
+
+	channels := tx.Model(&models.NotificationChannel{}).Select("id").Where("user_id = ?", userID)
+	if err := tx.Where("channel_id IN (?)", channels).Delete(&models.NotificationLog{}).Error; err != nil {
+		return err
+	}
+	if err := tx.Where("channel_id IN (?)", channels).Delete(&models.WebhookDelivery{}).Error; err != nil {
+		return err
+	}
+
+	// 共享工作区中引用该用户升级策略的资源不再升级
+	policies := tx.Model(&models.EscalationPolicy{}).Select("id").Where("user_id = ?", userID)
+	if err := tx.Model(&models.Resource{}).Where("escalation_policy_id IN (?)", policies).
+		Update("escalation_policy_id", nil).Error; err != nil {
+		return err
+	}
+
+	for _, model := range []interface{}{
+		&models.NotificationChannel{},
+		&models.APIToken{},
+		&models.RecoveryCode{},
+		&models.Session{},
+		&models.EscalationPolicy{},
+		&models.DigestSetting{},
+		&models.DigestHistory{},
+	} {
+		if err := tx.Where("user_id = ?", userID).Delete(model).Error; err != nil {
+			return err
+		}
+	}
+
+	return tx.Delete(&models.User{}, userID).Error
+}
+
+// hasWorkspaceAdmin 判断成员中是否有管理员
+func hasWorkspaceAdmin(members []models.WorkspaceMember) bool {
+	for _, m := range members {
+		if m.Role == models.WorkspaceAdmin {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,103 @@
+package handlers
+
+import (
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"tally/audit"
+	"tally/database"
+	"tally/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	defaultAuditPageSize = 50
+	maxAuditPageSize     = 200
+)
+
+// recordAudit 以当前用户为操作者记录审计事件，before 和 after 为操作前后的对象，创建时 before 为 nil，删除时 after 为 nil
+//
+// 记录失败只写日志，不影响已完成的操作
+func recordAudit(c *gin.Context, action, targetType string, targetID, workspaceID uint, before, after interface{}) {
+	actor := audit.Actor{
+		ID:        uint(c.MustGet("user_id").(float64)),
+		Name:      c.GetString("username"),
+		IP:        c.ClientIP(),
+		UserAgent: truncate(c.Request.UserAgent(), maxUserAgentLength),
+	}
+	if err := audit.Record(database.DB, actor, action, targetType, targetID, workspaceID, before, after); err != nil {
+		log.Printf("Failed to record audit event %s: %v", action, err)
+	}
+}
+
+// GetAuditEvents 分页查询审计事件，按时间倒序
+//
+// 管理员可查看全部事件，其他用户只能查看自己的操作和所在工作区内的事件。
+// 可按 actor_id、action、target_type、target_id、workspace_id 过滤，since、until 为 Unix 时间戳
+func GetAuditEvents(c *gin.Context) {
+	userID := uint(c.MustGet("user_id").(float64))
+
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	if page < 1 {
+		page = 1
+	}
+	pageSize, _ := strconv.Atoi(c.DefaultQuery("page_size", strconv.Itoa(defaultAuditPageSize)))
+	if pageSize < 1 || pageSize > maxAuditPageSize {
+		pageSize = defaultAuditPageSize
+	}
+
+	query := database.DB.Model(&models.AuditEvent{})
+	if c.GetString("role") != models.RoleAdmin {
+		workspaces := database.DB.Model(&models.WorkspaceMember{}).Select("workspace_id").Where("user_id = ?", userID)
+		query = query.Where("actor_id = ? OR workspace_id IN (?)", userID, workspaces)
+	}
+
+	for _, field := range []string{"actor_id", "target_id", "workspace_id"} {
+		if v := c.Query(field); v != "" {
+			id, err := strconv.ParseUint(v, 10, 32)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid " + field})
+				return
+			}
+			query = query.Where(field+" = ?", id)
+		}
+	}
+	if action := c.Query("action"); action != "" {
+		query = query.Where("action = ?", action)
+	}
+	if targetType := c.Query("target_type"); targetType != "" {
+		query = query.Where("target_type = ?", targetType)
+	}
+	for field, op := range map[string]string{"since": ">=", "until": "<"} {
+		if v := c.Query(field); v != "" {
+			ts, err := strconv.ParseInt(v, 10, 64)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid " + field})
+				return
+			}
+			query = query.Where("created_at "+op+" ?", time.Unix(ts, 0))
+		}
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch audit events"})
+		return
+	}
+
+	events := []models.AuditEvent{}
+	if err := query.Order("id DESC").Offset((page - 1) * pageSize).Limit(pageSize).Find(&events).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch audit events"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"events":    events,
+		"total":     total,
+		"page":      page,
+		"page_size": pageSize,
+	})
+}
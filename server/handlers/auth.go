@@ -2,6 +2,7 @@ package handlers
 
 import (
 	"net/http"
+	"sync"
 	"time"
 
 	"tally/config"
@@ -13,14 +14,41 @@ import (
 	"golang.org/x/crypto/bcrypt"
 )
 
+// twoFactorPurpose 两步验证挑战 token 的用途标识
+const twoFactorPurpose = "2fa"
+
+// dummyPasswordHash 用户名不存在时用于比对的占位哈希，与真实密码使用相同的 cost，
+// 使两种情况的响应耗时一致，避免通过耗时探测用户名是否存在
+var dummyPasswordHash = sync.OnceValue(func() []byte {
+	hash, _ := bcrypt.GenerateFromPassword([]byte("tally-dummy-password"), bcrypt.DefaultCost)
+	return hash
+})
+
 type LoginRequest struct {
 	Username string `json:"username" binding:"required"`
 	Password string `json:"password" binding:"required"`
 }
 
 type LoginResponse struct {
-	Token    string `json:"token"`
-	Username string `json:"username"`
+	Token        string `json:"token"`         // access token，有效期 15 分钟
+	RefreshToken string `json:"refresh_token"` // 刷新令牌，每次刷新后更换
+	ExpiresIn    int    `json:"expires_in"`    // access token 剩余秒数
+	Username     string `json:"username"`
+	Role         string `json:"role"`
+
+	MustChangePassword bool `json:"must_change_password"` // 为 true 时需先修改密码
+}
+
+// TwoFactorChallengeResponse 密码正确但需要两步验证时返回
+type TwoFactorChallengeResponse struct {
+	TwoFactorRequired bool   `json:"two_factor_required"`
+	ChallengeToken    string `json:"challenge_token"`
+}
+
+type LoginTwoFactorRequest struct {
+	ChallengeToken string `json:"challenge_token" binding:"required"`
+	Code           string `json:"code"`          // 验证器应用中的 6 位验证码
+	RecoveryCode   string `json:"recovery_code"` // 或一次性恢复码
 }
 
 func Login(c *gin.Context) {
@@ -33,31 +61,112 @@ func Login(c *gin.Context) {
 	// 查找用户
 	var user models.User
 	if err := database.DB.Where("username = ?", req.Username).First(&user).Error; err != nil {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid credentials"})
+		if !loginThrottled(c, req.Username, nil) {
+			bcrypt.CompareHashAndPassword(dummyPasswordHash(), []byte(req.Password))
+			recordLoginFailure(c, req.Username, nil, models.LoginReasonInvalidPassword)
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid credentials"})
+		}
+		return
+	}
+	if loginThrottled(c, req.Username, &user) {
 		return
 	}
 
 	// 验证密码
 	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(req.Password)); err != nil {
+		recordLoginFailure(c, req.Username, &user, models.LoginReasonInvalidPassword)
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid credentials"})
 		return
 	}
 
-	// 生成 JWT
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
-		"user_id":  user.ID,
-		"username": user.Username,
-		"exp":      time.Now().Add(time.Hour * config.JWTExpireHours).Unix(),
+	if user.Disabled {
+		recordLoginAttempt(c, user.Username, false, models.LoginReasonDisabled)
+		c.JSON(http.StatusForbidden, gin.H{"error": "Account disabled"})
+		return
+	}
+
+	// 启用两步验证时先返回短期挑战 token，验证通过后再签发正式 token
+	// 此时不清除失败计数，避免交替尝试密码和验证码绕过锁定
+	if user.TOTPEnabled {
+		challenge := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+			"user_id": user.ID,
+			"purpose": twoFactorPurpose,
+			"exp":     time.Now().Add(config.TwoFactorChallengeExpire).Unix(),
+		})
+		challengeString, err := challenge.SignedString([]byte(config.GetJWTSecret()))
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate token"})
+			return
+		}
+
+		c.JSON(http.StatusOK, TwoFactorChallengeResponse{
+			TwoFactorRequired: true,
+			ChallengeToken:    challengeString,
+		})
+		return
+	}
+
+	recordLoginSuccess(c, &user)
+	issueToken(c, &user)
+}
+
+// LoginTwoFactor 登录第二步，校验挑战 token 和验证码（或恢复码）后签发正式 token
+func LoginTwoFactor(c *gin.Context) {
+	var req LoginTwoFactorRequest
+	if err := c.ShouldBindJSON(&req); err != nil || (req.Code == "" && req.RecoveryCode == "") {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request, code or recovery_code is required"})
+		return
+	}
+
+	token, err := jwt.Parse(req.ChallengeToken, func(token *jwt.Token) (interface{}, error) {
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, jwt.ErrSignatureInvalid
+		}
+		return []byte(config.GetJWTSecret()), nil
 	})
+	if err != nil || !token.Valid {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or expired challenge"})
+		return
+	}
+	claims, _ := token.Claims.(jwt.MapClaims)
+	userID, ok := claims["user_id"].(float64)
+	if !ok || claims["purpose"] != twoFactorPurpose {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or expired challenge"})
+		return
+	}
 
-	tokenString, err := token.SignedString([]byte(config.GetJWTSecret()))
+	var user models.User
+	if err := database.DB.First(&user, uint(userID)).Error; err != nil || user.Disabled || !user.TOTPEnabled {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or expired challenge"})
+		return
+	}
+	if loginThrottled(c, user.Username, &user) {
+		return
+	}
+
+	if req.RecoveryCode != "" {
+		if !useRecoveryCode(user.ID, req.RecoveryCode) {
+			recordLoginFailure(c, user.Username, &user, models.LoginReasonInvalidCode)
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid recovery code"})
+			return
+		}
+	} else if !verifyTOTP(&user, req.Code) {
+		recordLoginFailure(c, user.Username, &user, models.LoginReasonInvalidCode)
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid code"})
+		return
+	}
+
+	recordLoginSuccess(c, &user)
+	issueToken(c, &user)
+}
+
+// issueToken 为已通过认证的用户创建会话，签发 access token 和刷新令牌
+func issueToken(c *gin.Context, user *models.User) {
+	resp, err := createSession(c, user)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate token"})
 		return
 	}
 
-	c.JSON(http.StatusOK, LoginResponse{
-		Token:    tokenString,
-		Username: user.Username,
-	})
+	c.JSON(http.StatusOK, resp)
 }
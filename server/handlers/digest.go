@@ -0,0 +1,164 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"tally/database"
+	"tally/models"
+	"tally/notifier"
+	"tally/scheduler"
+
+	"github.com/gin-gonic/gin"
+)
+
+type UpdateDigestSettingRequest struct {
+	Enabled    *bool   `json:"enabled"`
+	Frequency  *string `json:"frequency"` // daily / weekly
+	Hour       *int    `json:"hour"`
+	Weekday    *int    `json:"weekday"` // 0 为周日
+	Timezone   *string `json:"timezone"`
+	ChannelIDs *[]int  `json:"channel_ids"` // 为空时发送到所有启用的邮件渠道
+}
+
+// loadDigestSetting 获取用户的摘要设置，不存在时返回默认值（每日 09:00，服务器时区）
+func loadDigestSetting(userID uint) models.DigestSetting {
+	setting := models.DigestSetting{
+		UserID:    userID,
+		Frequency: notifier.DigestDaily,
+		Hour:      9,
+		Weekday:   int(time.Monday),
+		Timezone:  time.Local.String(),
+	}
+	database.DB.Where("user_id = ?", userID).First(&setting)
+	return setting
+}
+
+// GetDigestSetting 获取当前用户的摘要设置
+func GetDigestSetting(c *gin.Context) {
+	userID := uint(c.MustGet("user_id").(float64))
+
+	c.JSON(http.StatusOK, loadDigestSetting(userID))
+}
+
+// UpdateDigestSetting 更新当前用户的摘要设置
+func UpdateDigestSetting(c *gin.Context) {
+	userID := uint(c.MustGet("user_id").(float64))
+
+	var req UpdateDigestSettingRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request"})
+		return
+	}
+
+	setting := loadDigestSetting(userID)
+
+	// 更新提供的字段
+	if req.Enabled != nil {
+		setting.Enabled = *req.Enabled
+	}
+	if req.Frequency != nil {
+		if *req.Frequency != notifier.DigestDaily && *req.Frequency != notifier.DigestWeekly {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Frequency must be 'daily' or 'weekly'"})
+			return
+		}
+		setting.Frequency = *req.Frequency
+	}
+	if req.Hour != nil {
+		if *req.Hour < 0 || *req.Hour > 23 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Hour must be between 0 and 23"})
+			return
+		}
+		setting.Hour = *req.Hour
+	}
+	if req.Weekday != nil {
+		if *req.Weekday < 0 || *req.Weekday > 6 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Weekday must be between 0 (Sunday) and 6"})
+			return
+		}
+		setting.Weekday = *req.Weekday
+	}
+	if req.Timezone != nil {
+		if _, err := time.LoadLocation(*req.Timezone); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid timezone: " + *req.Timezone})
+			return
+		}
+		setting.Timezone = *req.Timezone
+	}
+	if req.ChannelIDs != nil {
+		if len(*req.ChannelIDs) > 0 {
+			var count int64
+			database.DB.Model(&models.NotificationChannel{}).
+				Where("user_id = ? AND id IN ?", userID, *req.ChannelIDs).
+				Count(&count)
+			if int(count) != len(*req.ChannelIDs) {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid channel_ids"})
+				return
+			}
+		}
+		setting.ChannelIDs = *req.ChannelIDs
+	}
+
+	// 从当前周期之后开始发送，避免保存后立即补发已过时间点的摘要
+	setting.LastPeriod = scheduler.DigestPeriod(&setting, time.Now())
+
+	if err := database.DB.Save(&setting).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update digest setting"})
+		return
+	}
+
+	c.JSON(http.StatusOK, setting)
+}
+
+// PreviewDigest 预览当前用户的摘要内容，不发送
+func PreviewDigest(c *gin.Context) {
+	userID := uint(c.MustGet("user_id").(float64))
+	setting := loadDigestSetting(userID)
+
+	digest, err := scheduler.BuildDigest(&setting)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to build digest"})
+		return
+	}
+
+	c.JSON(http.StatusOK, digest)
+}
+
+// SendDigestNow 立即发送一次摘要，不影响定时计划
+func SendDigestNow(c *gin.Context) {
+	userID := uint(c.MustGet("user_id").(float64))
+	setting := loadDigestSetting(userID)
+
+	history, err := scheduler.SendDigest(c.Request.Context(), &setting, "manual-"+time.Now().Format("2006-01-02T15:04:05"))
+	if errors.Is(err, scheduler.ErrEmptyDigest) {
+		c.JSON(http.StatusOK, gin.H{"message": "Digest is empty, nothing to send"})
+		return
+	}
+	if history == nil {
+		c.JSON(http.StatusBadGateway, gin.H{"error": "Failed to send digest: " + err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, history.ToResponse())
+}
+
+// GetDigestHistory 获取当前用户已发送的摘要记录（最近 100 条）
+func GetDigestHistory(c *gin.Context) {
+	userID := uint(c.MustGet("user_id").(float64))
+
+	var histories []models.DigestHistory
+	if err := database.DB.Where("user_id = ?", userID).
+		Order("sent_at DESC").
+		Limit(100).
+		Find(&histories).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch digest history"})
+		return
+	}
+
+	responses := make([]models.DigestHistoryResponse, len(histories))
+	for i, h := range histories {
+		responses[i] = h.ToResponse()
+	}
+	c.JSON(http.StatusOK, responses)
+}
@@ -0,0 +1,209 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"tally/database"
+	"tally/models"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type EscalationPolicyRequest struct {
+	Name        string                  `json:"name" binding:"required"`
+	TriggerDays int                     `json:"trigger_days"` // 剩余天数小于等于该值时开始升级
+	Steps       []models.EscalationStep `json:"steps" binding:"required"`
+}
+
+const (
+	maxEscalationSteps      = 10
+	maxEscalationAfterHours = 24 * 30
+	minEscalationTrigger    = -365
+)
+
+// validateEscalationPolicy 校验升级链，渠道需属于当前用户，用户需与当前用户同属某个工作区
+func validateEscalationPolicy(userID uint, req *EscalationPolicyRequest) error {
+	if req.TriggerDays < minEscalationTrigger || req.TriggerDays > maxReminderDays {
+		return fmt.Errorf("trigger_days must be between %d and %d", minEscalationTrigger, maxReminderDays)
+	}
+	if len(req.Steps) == 0 || len(req.Steps) > maxEscalationSteps {
+		return fmt.Errorf("steps must contain 1 to %d entries", maxEscalationSteps)
+	}
+
+	for i, step := range req.Steps {
+		if step.AfterHours < 0 || step.AfterHours > maxEscalationAfterHours {
+			return fmt.Errorf("steps[%d].after_hours must be between 0 and %d", i, maxEscalationAfterHours)
+		}
+		if (step.ChannelID == 0) == (step.UserID == 0) {
+			return fmt.Errorf("steps[%d] must set exactly one of channel_id or user_id", i)
+		}
+
+		var count int64
+		if step.ChannelID != 0 {
+			database.DB.Model(&models.NotificationChannel{}).
+				Where("id = ? AND user_id = ?", step.ChannelID, userID).Count(&count)
+			if count == 0 {
+				return fmt.Errorf("steps[%d]: channel %d not found", i, step.ChannelID)
+			}
+		} else {
+			// 用户不存在与不是成员返回相同的错误，避免探测用户 ID
+			database.DB.Model(&models.WorkspaceMember{}).
+				Where("user_id = ? AND workspace_id IN (?)", step.UserID,
+					database.DB.Model(&models.WorkspaceMember{}).Select("workspace_id").Where("user_id = ?", userID)).
+				Count(&count)
+			if count == 0 {
+				return fmt.Errorf("steps[%d]: user %d not found", i, step.UserID)
+			}
+		}
+	}
+	return nil
+}
+
+// findEscalationPolicy 按 ID 获取当前用户的升级策略
+func findEscalationPolicy(c *gin.Context, policy *models.EscalationPolicy) bool {
+	userID := uint(c.MustGet("user_id").(float64))
+	if err := database.DB.Where("id = ? AND user_id = ?", c.Param("id"), userID).First(policy).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Escalation policy not found"})
+		return false
+	}
+	return true
+}
+
+// checkEscalationPolicyID 校验资源引用的升级策略是否存在且属于当前用户
+func checkEscalationPolicyID(userID, id uint) error {
+	var count int64
+	database.DB.Model(&models.EscalationPolicy{}).Where("id = ? AND user_id = ?", id, userID).Count(&count)
+	if count == 0 {
+		return errors.New("escalation policy not found")
+	}
+	return nil
+}
+
+// stopEscalations 将符合条件且进行中的升级置为指定状态，db 可以是事务
+func stopEscalations(db *gorm.DB, status string, query interface{}, args ...interface{}) error {
+	return db.Model(&models.EscalationState{}).
+		Where("status = ?", models.EscalationActive).
+		Where(query, args...).
+		Updates(map[string]interface{}{"status": status, "next_at": nil}).Error
+}
+
+// GetEscalationPolicies 获取当前用户的升级策略
+func GetEscalationPolicies(c *gin.Context) {
+	userID := uint(c.MustGet("user_id").(float64))
+
+	var policies []models.EscalationPolicy
+	if err := database.DB.Where("user_id = ?", userID).Order("id").Find(&policies).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch escalation policies"})
+		return
+	}
+
+	c.JSON(http.StatusOK, policies)
+}
+
+// CreateEscalationPolicy 创建升级策略
+func CreateEscalationPolicy(c *gin.Context) {
+	userID := uint(c.MustGet("user_id").(float64))
+
+	var req EscalationPolicyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request: " + err.Error()})
+		return
+	}
+	if err := validateEscalationPolicy(userID, &req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	policy := models.EscalationPolicy{
+		UserID:      userID,
+		Name:        req.Name,
+		TriggerDays: req.TriggerDays,
+		Steps:       req.Steps,
+	}
+	if err := database.DB.Create(&policy).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create escalation policy"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, policy)
+}
+
+// UpdateEscalationPolicy 更新升级策略，进行中的升级按新的升级链继续
+func UpdateEscalationPolicy(c *gin.Context) {
+	userID := uint(c.MustGet("user_id").(float64))
+
+	var req EscalationPolicyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request: " + err.Error()})
+		return
+	}
+	if err := validateEscalationPolicy(userID, &req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var policy models.EscalationPolicy
+	if !findEscalationPolicy(c, &policy) {
+		return
+	}
+
+	policy.Name = req.Name
+	policy.TriggerDays = req.TriggerDays
+	policy.Steps = req.Steps
+	if err := database.DB.Save(&policy).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update escalation policy"})
+		return
+	}
+
+	c.JSON(http.StatusOK, policy)
+}
+
+// DeleteEscalationPolicy 删除升级策略，引用它的资源不再升级
+func DeleteEscalationPolicy(c *gin.Context) {
+	var policy models.EscalationPolicy
+	if !findEscalationPolicy(c, &policy) {
+		return
+	}
+
+	if err := database.DB.Delete(&policy).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete escalation policy"})
+		return
+	}
+
+	database.DB.Model(&models.Resource{}).Where("escalation_policy_id = ?", policy.ID).
+		Update("escalation_policy_id", nil)
+	stopEscalations(database.DB, models.EscalationResolved, "policy_id = ?", policy.ID)
+
+	c.JSON(http.StatusOK, gin.H{"message": "Escalation policy deleted"})
+}
+
+// GetEscalationStates 获取当前工作区资源的升级进度，可通过 status 过滤
+func GetEscalationStates(c *gin.Context) {
+	workspaceID := c.MustGet("workspace_id").(uint)
+
+	query := database.DB.
+		Where("resource_id IN (?)", database.DB.Model(&models.Resource{}).Select("id").Where("workspace_id = ?", workspaceID)).
+		Order("updated_at DESC")
+	if status := c.Query("status"); status != "" {
+		query = query.Where("status = ?", status)
+	}
+	if resourceID := c.Query("resource_id"); resourceID != "" {
+		query = query.Where("resource_id = ?", resourceID)
+	}
+
+	var states []models.EscalationState
+	if err := query.Limit(200).Find(&states).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch escalation states"})
+		return
+	}
+
+	responses := make([]models.EscalationStateResponse, len(states))
+	for i := range states {
+		responses[i] = states[i].ToResponse()
+	}
+
+	c.JSON(http.StatusOK, responses)
+}
@@ -0,0 +1,77 @@
+package handlers
+
+import (
+	"net/http"
+
+	"tally/config"
+	"tally/database"
+	"tally/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+type SetExchangeRateRequest struct {
+	Rate float64 `json:"rate" binding:"required,gt=0"` // 1 单位该货币折合多少基准货币
+}
+
+// GetExchangeRates 获取汇率表
+func GetExchangeRates(c *gin.Context) {
+	var rates []models.ExchangeRate
+	if err := database.DB.Order("currency").Find(&rates).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch exchange rates"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"base_currency": config.GetBaseCurrency(),
+		"rates":         rates,
+	})
+}
+
+// SetExchangeRate 设置货币相对基准货币的汇率（管理员）
+func SetExchangeRate(c *gin.Context) {
+	currency := models.NormalizeCurrency(c.Param("currency"))
+	if !models.ValidCurrency(currency) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Currency must be an ISO 4217 code"})
+		return
+	}
+	if currency == config.GetBaseCurrency() {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "The base currency always has a rate of 1"})
+		return
+	}
+
+	var req SetExchangeRateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request, rate must be greater than 0"})
+		return
+	}
+
+	var rate models.ExchangeRate
+	database.DB.Where("currency = ?", currency).Limit(1).Find(&rate)
+	rate.Currency = currency
+	rate.Rate = req.Rate
+
+	if err := database.DB.Save(&rate).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save exchange rate"})
+		return
+	}
+
+	c.JSON(http.StatusOK, rate)
+}
+
+// DeleteExchangeRate 删除汇率（管理员）
+func DeleteExchangeRate(c *gin.Context) {
+	currency := models.NormalizeCurrency(c.Param("currency"))
+
+	result := database.DB.Where("currency = ?", currency).Delete(&models.ExchangeRate{})
+	if result.Error != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete exchange rate"})
+		return
+	}
+	if result.RowsAffected == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Exchange rate not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Exchange rate deleted"})
+}
@@ -0,0 +1,120 @@
+package handlers
+
+import (
+	"net/http"
+
+	"tally/config"
+	"tally/database"
+	"tally/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+type UpdateGroupSettingRequest struct {
+	ReminderDays      *[]int `json:"reminder_days"`
+	RepeatAfterExpiry *int   `json:"repeat_after_expiry"`
+	ResetReminder     bool   `json:"reset_reminder"` // 为 true 时清除分组的提醒策略，恢复全局默认
+}
+
+// GroupSettingResponse 分组设置及其生效后的提醒策略
+type GroupSettingResponse struct {
+	GroupName         string                `json:"group"`
+	ReminderDays      models.IntList        `json:"reminder_days"`
+	RepeatAfterExpiry *int                  `json:"repeat_after_expiry"`
+	Effective         models.ReminderPolicy `json:"effective"`
+}
+
+// defaultReminderPolicy 全局默认提醒策略
+func defaultReminderPolicy() models.ReminderPolicy {
+	return models.ReminderPolicy{
+		Days:              config.GetReminderDays(),
+		RepeatAfterExpiry: config.GetReminderRepeatDays(),
+	}
+}
+
+// findGroupSetting 获取工作区的分组设置，不存在时返回未保存的新记录
+func findGroupSetting(workspaceID uint, name string) models.GroupSetting {
+	setting := models.GroupSetting{WorkspaceID: workspaceID, Name: name}
+	database.DB.Where("workspace_id = ? AND name = ?", workspaceID, name).First(&setting)
+	return setting
+}
+
+// toGroupSettingResponse 计算分组生效策略（分组 → 全局默认）
+func toGroupSettingResponse(setting models.GroupSetting) GroupSettingResponse {
+	return GroupSettingResponse{
+		GroupName:         setting.Name,
+		ReminderDays:      setting.ReminderDays,
+		RepeatAfterExpiry: setting.RepeatAfterExpiry,
+		Effective:         models.ResolveReminderPolicy(&models.Resource{}, &setting, defaultReminderPolicy()),
+	}
+}
+
+// GetGroupSettings 获取所有分组的提醒策略，以及全局默认策略
+func GetGroupSettings(c *gin.Context) {
+	workspaceID := c.MustGet("workspace_id").(uint)
+
+	var settings []models.GroupSetting
+	if err := database.DB.Where("workspace_id = ?", workspaceID).Order("name").Find(&settings).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch group settings"})
+		return
+	}
+
+	responses := make([]GroupSettingResponse, len(settings))
+	for i, s := range settings {
+		responses[i] = toGroupSettingResponse(s)
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"defaults": defaultReminderPolicy(),
+		"groups":   responses,
+	})
+}
+
+// GetGroupSetting 获取单个分组的提醒策略，未设置时返回继承的全局默认
+func GetGroupSetting(c *gin.Context) {
+	workspaceID := c.MustGet("workspace_id").(uint)
+	setting := findGroupSetting(workspaceID, c.Param("name"))
+
+	c.JSON(http.StatusOK, toGroupSettingResponse(setting))
+}
+
+// UpdateGroupSetting 设置分组的提醒策略
+func UpdateGroupSetting(c *gin.Context) {
+	name := c.Param("name")
+	workspaceID := c.MustGet("workspace_id").(uint)
+
+	var req UpdateGroupSettingRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request"})
+		return
+	}
+	var reminderDays []int
+	if req.ReminderDays != nil {
+		reminderDays = *req.ReminderDays
+	}
+	if err := validateReminderPolicy(reminderDays, req.RepeatAfterExpiry); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	setting := findGroupSetting(workspaceID, name)
+
+	// 更新提供的字段
+	if req.ResetReminder {
+		setting.ReminderDays = nil
+		setting.RepeatAfterExpiry = nil
+	}
+	if req.ReminderDays != nil {
+		setting.ReminderDays = models.NormalizeReminderDays(*req.ReminderDays)
+	}
+	if req.RepeatAfterExpiry != nil {
+		setting.RepeatAfterExpiry = req.RepeatAfterExpiry
+	}
+
+	if err := database.DB.Save(&setting).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update group setting"})
+		return
+	}
+
+	c.JSON(http.StatusOK, toGroupSettingResponse(setting))
+}
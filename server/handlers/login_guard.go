@@ -0,0 +1,176 @@
+package handlers
+
+import (
+	"log"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"tally/config"
+	"tally/database"
+	"tally/models"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// loginFreeFailures 连续失败次数不超过该值时不要求等待
+const loginFreeFailures = 2
+
+// loginDelay 根据失败次数计算下次尝试前需等待的时间：第 3 次失败后 1 秒，之后每次翻倍，最多 LoginDelayMax
+func loginDelay(failures int) time.Duration {
+	if failures <= loginFreeFailures {
+		return 0
+	}
+	shift := failures - loginFreeFailures - 1
+	if shift > 10 {
+		return config.LoginDelayMax
+	}
+	delay := time.Second << uint(shift)
+	if delay > config.LoginDelayMax {
+		return config.LoginDelayMax
+	}
+	return delay
+}
+
+// loginThrottled 检查来源 IP 和账户的限流、锁定状态，被限制时写入响应并返回 true
+//
+// user 为 nil 表示用户名不存在，此时只按 IP 和用户名的失败记录限流
+func loginThrottled(c *gin.Context, username string, user *models.User) bool {
+	now := time.Now()
+	ip := c.ClientIP()
+
+	if user != nil && user.Locked(now) {
+		setRetryAfter(c, user.LockedUntil.Sub(now))
+		c.JSON(http.StatusLocked, gin.H{
+			"error":        "Account temporarily locked due to too many failed login attempts",
+			"locked_until": user.LockedUntil.Unix(),
+		})
+		return true
+	}
+
+	since := now.Add(-config.LoginFailureWindow)
+	var ipFailures int64
+	database.DB.Model(&models.LoginAttempt{}).
+		Where("ip = ? AND success = ? AND created_at > ?", ip, false, since).
+		Count(&ipFailures)
+	if ipFailures >= config.LoginIPMaxFailures {
+		setRetryAfter(c, config.LoginFailureWindow)
+		c.JSON(http.StatusTooManyRequests, gin.H{"error": "Too many failed login attempts, try again later"})
+		return true
+	}
+
+	// 渐进延迟：取 IP 与账户中较多的连续失败次数，距上次失败不足等待时间时拒绝
+	failures := int(ipFailures)
+	if user != nil && user.FailedLogins > failures {
+		failures = user.FailedLogins
+	}
+	if delay := loginDelay(failures); delay > 0 {
+		var last models.LoginAttempt
+		err := database.DB.
+			Where("(ip = ? OR username = ?) AND success = ? AND created_at > ?", ip, username, false, since).
+			Order("created_at DESC").
+			First(&last).Error
+		if err == nil {
+			if wait := last.CreatedAt.Add(delay).Sub(now); wait > 0 {
+				setRetryAfter(c, wait)
+				c.JSON(http.StatusTooManyRequests, gin.H{"error": "Too many failed login attempts, try again later"})
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// recordLoginFailure 记录失败的登录尝试，账户连续失败达到上限后锁定
+func recordLoginFailure(c *gin.Context, username string, user *models.User, reason string) {
+	recordLoginAttempt(c, username, false, reason)
+	if user == nil {
+		return
+	}
+
+	database.DB.Model(&models.User{}).Where("id = ?", user.ID).
+		UpdateColumn("failed_logins", gorm.Expr("failed_logins + 1"))
+
+	maxFailures := config.GetLoginMaxFailures()
+	if maxFailures == 0 {
+		return
+	}
+	lockedUntil := time.Now().Add(config.GetLoginLockoutDuration())
+	result := database.DB.Model(&models.User{}).
+		Where("id = ? AND failed_logins >= ?", user.ID, maxFailures).
+		UpdateColumns(map[string]interface{}{"failed_logins": 0, "locked_until": lockedUntil})
+	if result.RowsAffected > 0 {
+		log.Printf("User %q locked until %s after %d failed login attempts", user.Username, lockedUntil.Format(time.RFC3339), maxFailures)
+	}
+}
+
+// recordLoginSuccess 记录成功的登录，并清除失败计数
+func recordLoginSuccess(c *gin.Context, user *models.User) {
+	recordLoginAttempt(c, user.Username, true, "")
+	if user.FailedLogins != 0 || user.LockedUntil != nil {
+		database.DB.Model(&models.User{}).Where("id = ?", user.ID).
+			UpdateColumns(map[string]interface{}{"failed_logins": 0, "locked_until": nil})
+	}
+}
+
+func recordLoginAttempt(c *gin.Context, username string, success bool, reason string) {
+	attempt := models.LoginAttempt{
+		Username:  truncate(username, maxUserAgentLength),
+		IP:        c.ClientIP(),
+		UserAgent: truncate(c.Request.UserAgent(), maxUserAgentLength),
+		Success:   success,
+		Reason:    reason,
+	}
+	if err := database.DB.Create(&attempt).Error; err != nil {
+		log.Printf("Failed to record login attempt: %v", err)
+	}
+}
+
+func setRetryAfter(c *gin.Context, d time.Duration) {
+	c.Header("Retry-After", strconv.Itoa(int(math.Ceil(d.Seconds()))))
+}
+
+// UnlockUser 解除用户的登录锁定（管理员）
+func UnlockUser(c *gin.Context) {
+	var user models.User
+	if !findUser(c, &user) {
+		return
+	}
+
+	if err := database.DB.Model(&user).
+		UpdateColumns(map[string]interface{}{"failed_logins": 0, "locked_until": nil}).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to unlock user"})
+		return
+	}
+
+	unlocked := user
+	unlocked.FailedLogins = 0
+	unlocked.LockedUntil = nil
+	recordAudit(c, models.AuditUserUnlock, models.AuditTargetUser, user.ID, 0, user, unlocked)
+	c.JSON(http.StatusOK, gin.H{"message": "User unlocked successfully"})
+}
+
+// GetLoginAttempts 获取登录尝试记录（管理员，最近 200 条），可按 username、ip、success 过滤
+func GetLoginAttempts(c *gin.Context) {
+	query := database.DB.Model(&models.LoginAttempt{})
+	if username := c.Query("username"); username != "" {
+		query = query.Where("username = ?", username)
+	}
+	if ip := c.Query("ip"); ip != "" {
+		query = query.Where("ip = ?", ip)
+	}
+	if success := c.Query("success"); success != "" {
+		query = query.Where("success = ?", success == "true")
+	}
+
+	var attempts []models.LoginAttempt
+	if err := query.Order("id DESC").Limit(200).Find(&attempts).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch login attempts"})
+		return
+	}
+
+	c.JSON(http.StatusOK, attempts)
+}
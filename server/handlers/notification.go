@@ -0,0 +1,322 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"tally/database"
+	"tally/models"
+	"tally/notifier"
+
+	"github.com/gin-gonic/gin"
+)
+
+type CreateNotificationChannelRequest struct {
+	Name     string          `json:"name" binding:"required"`
+	Type     string          `json:"type" binding:"required"`
+	Enabled  *bool           `json:"enabled"`
+	Language string          `json:"language"` // zh / en，默认 zh
+	Config   json.RawMessage `json:"config"`
+
+	QuietStart    string `json:"quiet_start"` // HH:MM
+	QuietEnd      string `json:"quiet_end"`
+	QuietTimezone string `json:"quiet_timezone"`
+}
+
+type UpdateNotificationChannelRequest struct {
+	Name     *string         `json:"name"`
+	Enabled  *bool           `json:"enabled"`
+	Language *string         `json:"language"`
+	Config   json.RawMessage `json:"config"`
+
+	QuietStart    *string `json:"quiet_start"`
+	QuietEnd      *string `json:"quiet_end"`
+	QuietTimezone *string `json:"quiet_timezone"`
+}
+
+type TestNotificationRequest struct {
+	Type     string          `json:"type" binding:"required"`
+	Language string          `json:"language"`
+	Config   json.RawMessage `json:"config"`
+}
+
+// GetNotificationTypes 获取支持的通知渠道类型
+func GetNotificationTypes(c *gin.Context) {
+	c.JSON(http.StatusOK, notifier.Types())
+}
+
+// GetNotificationChannels 获取当前用户的通知渠道
+func GetNotificationChannels(c *gin.Context) {
+	userID := uint(c.MustGet("user_id").(float64))
+
+	var channels []models.NotificationChannel
+	if err := database.DB.Where("user_id = ?", userID).Order("id").Find(&channels).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch notification channels"})
+		return
+	}
+
+	responses := make([]models.NotificationChannelResponse, len(channels))
+	for i, ch := range channels {
+		responses[i] = ch.ToResponse()
+	}
+	c.JSON(http.StatusOK, responses)
+}
+
+// CreateNotificationChannel 创建通知渠道
+func CreateNotificationChannel(c *gin.Context) {
+	userID := uint(c.MustGet("user_id").(float64))
+
+	var req CreateNotificationChannelRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request: " + err.Error()})
+		return
+	}
+
+	if req.Language == "" {
+		req.Language = notifier.DefaultLanguage
+	}
+	if !notifier.IsSupportedLanguage(req.Language) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Unsupported language: " + req.Language})
+		return
+	}
+	if err := notifier.ValidateQuietHours(req.QuietStart, req.QuietEnd, req.QuietTimezone); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	channel := models.NotificationChannel{
+		UserID:        userID,
+		Name:          req.Name,
+		Type:          req.Type,
+		Enabled:       req.Enabled == nil || *req.Enabled,
+		Language:      req.Language,
+		Config:        string(req.Config),
+		QuietStart:    req.QuietStart,
+		QuietEnd:      req.QuietEnd,
+		QuietTimezone: req.QuietTimezone,
+	}
+
+	// 校验渠道类型与配置
+	if _, err := notifier.New(&channel); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if !checkChannelTargets(c, &channel) {
+		return
+	}
+
+	if err := database.DB.Create(&channel).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create notification channel"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, channel.ToResponse())
+}
+
+// UpdateNotificationChannel 更新通知渠道
+func UpdateNotificationChannel(c *gin.Context) {
+	userID := uint(c.MustGet("user_id").(float64))
+	id := c.Param("id")
+
+	var req UpdateNotificationChannelRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request"})
+		return
+	}
+
+	var channel models.NotificationChannel
+	if err := database.DB.Where("user_id = ?", userID).First(&channel, id).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Notification channel not found"})
+		return
+	}
+
+	// 更新提供的字段
+	if req.Name != nil {
+		channel.Name = *req.Name
+	}
+	if req.Enabled != nil {
+		channel.Enabled = *req.Enabled
+	}
+	if req.Language != nil {
+		if !notifier.IsSupportedLanguage(*req.Language) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Unsupported language: " + *req.Language})
+			return
+		}
+		channel.Language = *req.Language
+	}
+	if req.QuietStart != nil {
+		channel.QuietStart = *req.QuietStart
+	}
+	if req.QuietEnd != nil {
+		channel.QuietEnd = *req.QuietEnd
+	}
+	if req.QuietTimezone != nil {
+		channel.QuietTimezone = *req.QuietTimezone
+	}
+	if err := notifier.ValidateQuietHours(channel.QuietStart, channel.QuietEnd, channel.QuietTimezone); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if req.Config != nil {
+		// 提交回来的 SecretMask 表示密钥未修改
+		channel.Config = models.RestoreSecretConfig(string(req.Config), channel.Config)
+		if _, err := notifier.New(&channel); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if !checkChannelTargets(c, &channel) {
+			return
+		}
+	}
+
+	if err := database.DB.Save(&channel).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update notification channel"})
+		return
+	}
+
+	c.JSON(http.StatusOK, channel.ToResponse())
+}
+
+// DeleteNotificationChannel 删除通知渠道及其发送记录
+func DeleteNotificationChannel(c *gin.Context) {
+	userID := uint(c.MustGet("user_id").(float64))
+	id := c.Param("id")
+
+	var channel models.NotificationChannel
+	if err := database.DB.Where("user_id = ?", userID).First(&channel, id).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Notification channel not found"})
+		return
+	}
+
+	if err := database.DB.Where("channel_id = ?", channel.ID).Delete(&models.NotificationLog{}).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete notification logs"})
+		return
+	}
+	if err := database.DB.Where("channel_id = ?", channel.ID).Delete(&models.WebhookDelivery{}).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete webhook deliveries"})
+		return
+	}
+	if err := database.DB.Delete(&channel).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete notification channel"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Notification channel deleted"})
+}
+
+// TestNotificationChannel 通过已保存的渠道发送测试消息
+func TestNotificationChannel(c *gin.Context) {
+	userID := uint(c.MustGet("user_id").(float64))
+	id := c.Param("id")
+
+	var channel models.NotificationChannel
+	if err := database.DB.Where("user_id = ?", userID).First(&channel, id).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Notification channel not found"})
+		return
+	}
+
+	if err := notifier.Send(c.Request.Context(), &channel, notifier.TestMessage()); err != nil {
+		// 普通用户看不到对方的响应内容
+		msg := err.Error()
+		if c.GetString("role") != models.RoleAdmin {
+			msg = notifier.PublicMessage(err)
+		}
+		c.JSON(http.StatusBadGateway, gin.H{"error": "Failed to send test notification: " + msg})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Test notification sent"})
+}
+
+// TestNotificationConfig 使用未保存的配置发送测试消息（管理员），可请求任意地址，不向普通用户开放
+func TestNotificationConfig(c *gin.Context) {
+	var req TestNotificationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request: " + err.Error()})
+		return
+	}
+
+	channel := models.NotificationChannel{
+		UserID:   uint(c.MustGet("user_id").(float64)),
+		Name:     "test",
+		Type:     req.Type,
+		Language: req.Language,
+		Config:   string(req.Config),
+	}
+	if _, err := notifier.New(&channel); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := notifier.Send(c.Request.Context(), &channel, notifier.TestMessage()); err != nil {
+		c.JSON(http.StatusBadGateway, gin.H{"error": "Failed to send test notification: " + err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Test notification sent"})
+}
+
+// GetNotificationLogs 获取当前用户渠道的提醒发送记录
+func GetNotificationLogs(c *gin.Context) {
+	userID := uint(c.MustGet("user_id").(float64))
+
+	var logs []models.NotificationLog
+	if err := database.DB.
+		Where("channel_id IN (?)", database.DB.Model(&models.NotificationChannel{}).Select("id").Where("user_id = ?", userID)).
+		Order("sent_at DESC").
+		Limit(200).
+		Find(&logs).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch notification logs"})
+		return
+	}
+
+	responses := make([]models.NotificationLogResponse, len(logs))
+	for i, l := range logs {
+		responses[i] = l.ToResponse()
+	}
+	c.JSON(http.StatusOK, responses)
+}
+
+// GetWebhookDeliveries 获取渠道的 Webhook 投递记录（最近 200 条）
+func GetWebhookDeliveries(c *gin.Context) {
+	userID := uint(c.MustGet("user_id").(float64))
+	id := c.Param("id")
+
+	var channel models.NotificationChannel
+	if err := database.DB.Where("user_id = ?", userID).First(&channel, id).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Notification channel not found"})
+		return
+	}
+
+	var deliveries []models.WebhookDelivery
+	if err := database.DB.Where("channel_id = ?", channel.ID).
+		Order("id DESC").
+		Limit(200).
+		Find(&deliveries).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch webhook deliveries"})
+		return
+	}
+
+	// 对方的响应内容只向管理员展示
+	admin := c.GetString("role") == models.RoleAdmin
+	responses := make([]models.WebhookDeliveryResponse, len(deliveries))
+	for i, d := range deliveries {
+		responses[i] = d.ToResponse()
+		if !admin {
+			responses[i].ResponseBody = ""
+		}
+	}
+	c.JSON(http.StatusOK, responses)
+}
+
+// checkChannelTargets 普通用户的渠道不能指向内网、回环和链路本地地址，不符合时写入 400 响应并返回 false
+func checkChannelTargets(c *gin.Context, channel *models.NotificationChannel) bool {
+	if !notifier.TargetsRestricted(c.GetString("role")) {
+		return true
+	}
+	if err := notifier.CheckTargets(c.Request.Context(), channel); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return false
+	}
+	return true
+}
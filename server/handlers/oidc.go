@@ -0,0 +1,280 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"tally/config"
+	"tally/database"
+	"tally/models"
+	"tally/oidc"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+)
+
+const (
+	// oidcPurpose 登录状态 token 的用途标识
+	oidcPurpose = "oidc"
+	// oidcStateCookie 保存 state、nonce 和 PKCE code_verifier 的 Cookie
+	oidcStateCookie = "tally_oidc"
+	oidcCookiePath  = "/api/auth/oidc"
+	oidcStateExpire = 10 * time.Minute
+)
+
+var (
+	oidcClient     *oidc.Client
+	oidcClientOnce sync.Once
+)
+
+// AuthProvidersResponse 登录页可用的登录方式
+type AuthProvidersResponse struct {
+	Password bool              `json:"password"`
+	OIDC     *OIDCProviderInfo `json:"oidc"`
+}
+
+type OIDCProviderInfo struct {
+	Name     string `json:"name"`
+	LoginURL string `json:"login_url"`
+}
+
+func getOIDCClient() *oidc.Client {
+	oidcClientOnce.Do(func() {
+		cfg := config.GetOIDCConfig()
+		oidcClient = oidc.NewClient(oidc.Config{
+			Issuer:       cfg.Issuer,
+			ClientID:     cfg.ClientID,
+			ClientSecret: cfg.ClientSecret,
+			RedirectURL:  cfg.RedirectURL,
+			Scopes:       cfg.Scopes,
+		})
+	})
+	return oidcClient
+}
+
+// GetAuthProviders 获取可用的登录方式，未配置 OIDC 时 oidc 为 null
+func GetAuthProviders(c *gin.Context) {
+	resp := AuthProvidersResponse{Password: true}
+	if cfg := config.GetOIDCConfig(); cfg.Enabled() {
+		resp.OIDC = &OIDCProviderInfo{Name: cfg.ProviderName, LoginURL: oidcCookiePath + "/login"}
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+// OIDCLogin 生成 state、nonce 和 PKCE 参数后跳转到身份提供方
+func OIDCLogin(c *gin.Context) {
+	if !config.GetOIDCConfig().Enabled() {
+		c.JSON(http.StatusNotFound, gin.H{"error": "OIDC login is not configured"})
+		return
+	}
+
+	var values [3]string
+	for i := range values {
+		v, err := oidc.RandomString()
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to start OIDC login"})
+			return
+		}
+		values[i] = v
+	}
+	state, nonce, verifier := values[0], values[1], values[2]
+
+	authURL, err := getOIDCClient().AuthCodeURL(c.Request.Context(), state, nonce, verifier)
+	if err != nil {
+		log.Printf("OIDC login failed: %v", err)
+		c.JSON(http.StatusBadGateway, gin.H{"error": "OIDC provider unavailable"})
+		return
+	}
+
+	// 登录状态签名后存入 Cookie，回调时校验，无需服务端保存
+	stateToken := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"purpose":  oidcPurpose,
+		"state":    state,
+		"nonce":    nonce,
+		"verifier": verifier,
+		"exp":      time.Now().Add(oidcStateExpire).Unix(),
+	})
+	stateString, err := stateToken.SignedString([]byte(config.GetJWTSecret()))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to start OIDC login"})
+		return
+	}
+
+	c.SetSameSite(http.SameSiteLaxMode)
+	c.SetCookie(oidcStateCookie, stateString, int(oidcStateExpire.Seconds()), oidcCookiePath, "", isHTTPS(c), true)
+	c.Redirect(http.StatusFound, authURL)
+}
+
+// OIDCCallback 校验回调并换取 ID Token，登录成功后携带 JWT 跳转回前端（放在 URL fragment 中，不会发送到服务器）
+func OIDCCallback(c *gin.Context) {
+	cookie, _ := c.Cookie(oidcStateCookie)
+	c.SetSameSite(http.SameSiteLaxMode)
+	c.SetCookie(oidcStateCookie, "", -1, oidcCookiePath, "", isHTTPS(c), true)
+
+	if !config.GetOIDCConfig().Enabled() {
+		c.JSON(http.StatusNotFound, gin.H{"error": "OIDC login is not configured"})
+		return
+	}
+	if e := c.Query("error"); e != "" {
+		oidcRedirectError(c, "OIDC provider returned error: "+e)
+		return
+	}
+
+	claims, ok := parseOIDCState(cookie)
+	if !ok || c.Query("state") == "" || claims["state"] != c.Query("state") {
+		oidcRedirectError(c, "Invalid or expired OIDC login state")
+		return
+	}
+	nonce, _ := claims["nonce"].(string)
+	verifier, _ := claims["verifier"].(string)
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 30*time.Second)
+	defer cancel()
+	idClaims, err := getOIDCClient().Exchange(ctx, c.Query("code"), verifier, nonce)
+	if err != nil {
+		log.Printf("OIDC callback failed: %v", err)
+		oidcRedirectError(c, "OIDC login failed")
+		return
+	}
+
+	user, err := provisionOIDCUser(idClaims)
+	if err != nil {
+		oidcRedirectError(c, err.Error())
+		return
+	}
+	if user.Disabled {
+		oidcRedirectError(c, "Account disabled")
+		return
+	}
+
+	// 两步验证由身份提供方负责，这里直接签发正式 token
+	resp, err := createSession(c, user)
+	if err != nil {
+		oidcRedirectError(c, "Failed to generate token")
+		return
+	}
+	fragment := url.Values{}
+	fragment.Set("token", resp.Token)
+	fragment.Set("refresh_token", resp.RefreshToken)
+	c.Redirect(http.StatusFound, "/#"+fragment.Encode())
+}
+
+// parseOIDCState 校验 Cookie 中的登录状态
+func parseOIDCState(tokenString string) (jwt.MapClaims, bool) {
+	if tokenString == "" {
+		return nil, false
+	}
+	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, jwt.ErrSignatureInvalid
+		}
+		return []byte(config.GetJWTSecret()), nil
+	})
+	if err != nil || !token.Valid {
+		return nil, false
+	}
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok || claims["purpose"] != oidcPurpose {
+		return nil, false
+	}
+	return claims, true
+}
+
+// provisionOIDCUser 按 sub 查找绑定的用户，首次登录时自动创建，并按配置同步管理员角色
+func provisionOIDCUser(claims jwt.MapClaims) (*models.User, error) {
+	cfg := config.GetOIDCConfig()
+	sub, _ := claims["sub"].(string)
+
+	var user models.User
+	err := database.DB.Where("oidc_subject = ?", sub).First(&user).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		username := oidcUsername(claims, cfg.UsernameClaim)
+
+		// 不自动绑定同名的本地账号，避免身份提供方的用户接管本地账户
+		var count int64
+		database.DB.Model(&models.User{}).Where("username = ?", username).Count(&count)
+		if count > 0 {
+			return nil, fmt.Errorf("username %q is already used by a local account", username)
+		}
+
+		// 单点登录用户不使用密码，随机密码仅用于满足非空约束
+		random, err := oidc.RandomString()
+		if err != nil {
+			return nil, errors.New("failed to create user")
+		}
+		hashed, err := bcrypt.GenerateFromPassword([]byte(random), bcrypt.DefaultCost)
+		if err != nil {
+			return nil, errors.New("failed to create user")
+		}
+
+		user = models.User{
+			Username:    username,
+			Password:    string(hashed),
+			Role:        models.RoleUser,
+			OIDCSubject: &sub,
+		}
+		if err := database.DB.Create(&user).Error; err != nil {
+			return nil, errors.New("failed to create user")
+		}
+	} else if err != nil {
+		return nil, errors.New("failed to load user")
+	}
+
+	if cfg.AdminValue != "" {
+		role := models.RoleUser
+		if claimContains(claims[cfg.AdminClaim], cfg.AdminValue) {
+			role = models.RoleAdmin
+		}
+		// 最后一个管理员不会因为声明变化而被降级
+		if role != user.Role && !(role == models.RoleUser && isLastAdmin(&user)) {
+			user.Role = role
+			if err := database.DB.Model(&user).Update("role", role).Error; err != nil {
+				return nil, errors.New("failed to update user role")
+			}
+		}
+	}
+
+	return &user, nil
+}
+
+// oidcUsername 从声明中取用户名，依次回退到 email 和 sub
+func oidcUsername(claims jwt.MapClaims, claim string) string {
+	for _, key := range []string{claim, "email", "sub"} {
+		if v, ok := claims[key].(string); ok && v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+// claimContains 判断声明（字符串或字符串数组）是否包含指定值
+func claimContains(claim interface{}, value string) bool {
+	switch v := claim.(type) {
+	case string:
+		return v == value
+	case []interface{}:
+		for _, item := range v {
+			if s, ok := item.(string); ok && s == value {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func oidcRedirectError(c *gin.Context, message string) {
+	c.Redirect(http.StatusFound, "/#oidc_error="+url.QueryEscape(message))
+}
+
+// isHTTPS 判断请求是否通过 HTTPS 访问（包括反向代理终止 TLS 的情况）
+func isHTTPS(c *gin.Context) bool {
+	return c.Request.TLS != nil || c.GetHeader("X-Forwarded-Proto") == "https"
+}
@@ -0,0 +1,304 @@
+package handlers
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"tally/database"
+	"tally/models"
+	"tally/oidc"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	testClientID     = "tally-test"
+	testClientSecret = "client-secret"
+	testRedirectURL  = "http://tally.example.com/api/auth/oidc/callback"
+	testAuthCode     = "good-code"
+)
+
+// fakeIssuer 本地 OIDC 身份提供方替身，提供发现文档、JWKS 和 token 端点
+type fakeIssuer struct {
+	server *httptest.Server
+	key    *rsa.PrivateKey
+
+	mu        sync.Mutex
+	challenge string // 授权请求中的 code_challenge
+	nonce     string // 写入 ID Token 的 nonce
+	audience  string
+	claims    jwt.MapClaims // 额外写入 ID Token 的声明
+}
+
+func newFakeIssuer(t *testing.T) *fakeIssuer {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+	issuer := &fakeIssuer{key: key, audience: testClientID}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(oidc.Provider{
+			Issuer:                issuer.server.URL,
+			AuthorizationEndpoint: issuer.server.URL + "/authorize",
+			TokenEndpoint:         issuer.server.URL + "/token",
+			JWKSURI:               issuer.server.URL + "/jwks",
+		})
+	})
+	mux.HandleFunc("/jwks", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"keys": []map[string]string{{
+				"kid": "k1",
+				"kty": "RSA",
+				"use": "sig",
+				"n":   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+				"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+			}},
+		})
+	})
+	mux.HandleFunc("/token", issuer.token)
+	issuer.server = httptest.NewServer(mux)
+	t.Cleanup(issuer.server.Close)
+	return issuer
+}
+
+// token 校验授权码、客户端凭据和 PKCE 后签发 ID Token
+func (i *fakeIssuer) token(w http.ResponseWriter, r *http.Request) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	id, secret, _ := r.BasicAuth()
+	if r.Method != http.MethodPost || id != testClientID || secret != testClientSecret {
+		http.Error(w, `{"error":"invalid_client"}`, http.StatusUnauthorized)
+		return
+	}
+	if r.PostFormValue("grant_type") != "authorization_code" || r.PostFormValue("code") != testAuthCode ||
+		r.PostFormValue("redirect_uri") != testRedirectURL ||
+		oidc.CodeChallenge(r.PostFormValue("code_verifier")) != i.challenge {
+		http.Error(w, `{"error":"invalid_grant"}`, http.StatusBadRequest)
+		return
+	}
+
+	claims := jwt.MapClaims{
+		"iss":   i.server.URL,
+		"aud":   i.audience,
+		"nonce": i.nonce,
+		"iat":   time.Now().Unix(),
+		"exp":   time.Now().Add(5 * time.Minute).Unix(),
+	}
+	for k, v := range i.claims {
+		claims[k] = v
+	}
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	token.Header["kid"] = "k1"
+	idToken, err := token.SignedString(i.key)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	json.NewEncoder(w).Encode(map[string]string{"access_token": "at", "token_type": "Bearer", "id_token": idToken})
+}
+
+// setupOIDCTest 在临时目录中初始化数据库，并将 OIDC 配置指向本地身份提供方
+func setupOIDCTest(t *testing.T) (*gin.Engine, *fakeIssuer) {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(t.TempDir()); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.Chdir(wd) })
+
+	issuer := newFakeIssuer(t)
+	t.Setenv("JWT_SECRET", "test-jwt-secret")
+	t.Setenv("ADMIN_PASSWORD", "admin-password")
+	t.Setenv("OIDC_ISSUER", issuer.server.URL)
+	t.Setenv("OIDC_CLIENT_ID", testClientID)
+	t.Setenv("OIDC_CLIENT_SECRET", testClientSecret)
+	t.Setenv("OIDC_REDIRECT_URL", testRedirectURL)
+	t.Setenv("OIDC_ADMIN_VALUE", "tally-admins")
+	database.InitDB()
+
+	r := gin.New()
+	r.GET("/api/auth/oidc/login", OIDCLogin)
+	r.GET("/api/auth/oidc/callback", OIDCCallback)
+	return r, issuer
+}
+
+// oidcLogin 发起登录，返回 state 与登录状态 Cookie，并把 nonce 和 code_challenge 交给身份提供方
+func oidcLogin(t *testing.T, r *gin.Engine, issuer *fakeIssuer) (string, *http.Cookie) {
+	t.Helper()
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/auth/oidc/login", nil))
+	if w.Code != http.StatusFound {
+		t.Fatalf("login status = %d: %s", w.Code, w.Body.String())
+	}
+
+	location, err := url.Parse(w.Header().Get("Location"))
+	if err != nil || !strings.HasPrefix(location.String(), issuer.server.URL+"/authorize?") {
+		t.Fatalf("login redirect = %q", w.Header().Get("Location"))
+	}
+	q := location.Query()
+	if q.Get("client_id") != testClientID || q.Get("redirect_uri") != testRedirectURL ||
+		q.Get("response_type") != "code" || q.Get("code_challenge_method") != "S256" || q.Get("scope") != "openid profile email" {
+		t.Errorf("authorization request = %v", q)
+	}
+
+	var cookie *http.Cookie
+	for _, c := range w.Result().Cookies() {
+		if c.Name == oidcStateCookie {
+			cookie = c
+		}
+	}
+	if cookie == nil || !cookie.HttpOnly || cookie.Path != oidcCookiePath {
+		t.Fatalf("state cookie = %+v", cookie)
+	}
+
+	issuer.mu.Lock()
+	issuer.challenge = q.Get("code_challenge")
+	issuer.nonce = q.Get("nonce")
+	issuer.mu.Unlock()
+	return q.Get("state"), cookie
+}
+
+// oidcCallback 模拟身份提供方跳转回回调地址，返回回调的跳转地址
+func oidcCallback(t *testing.T, r *gin.Engine, code, state string, cookie *http.Cookie) string {
+	t.Helper()
+	req := httptest.NewRequest(http.MethodGet, "/api/auth/oidc/callback?"+url.Values{"code": {code}, "state": {state}}.Encode(), nil)
+	if cookie != nil {
+		req.AddCookie(cookie)
+	}
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusFound {
+		t.Fatalf("callback status = %d: %s", w.Code, w.Body.String())
+	}
+	return w.Header().Get("Location")
+}
+
+func (i *fakeIssuer) setClaims(claims jwt.MapClaims) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	i.claims = claims
+}
+
+func oidcError(location string) string {
+	values, _ := url.ParseQuery(strings.TrimPrefix(location, "/#"))
+	return values.Get("oidc_error")
+}
+
+func TestOIDCLoginCallback(t *testing.T) {
+	r, issuer := setupOIDCTest(t)
+
+	t.Run("provisions user", func(t *testing.T) {
+		issuer.setClaims(jwt.MapClaims{"sub": "user-123", "preferred_username": "alice", "groups": []string{"staff", "tally-admins"}})
+		state, cookie := oidcLogin(t, r, issuer)
+		location := oidcCallback(t, r, testAuthCode, state, cookie)
+
+		fragment, err := url.ParseQuery(strings.TrimPrefix(location, "/#"))
+		if err != nil || fragment.Get("token") == "" || fragment.Get("refresh_token") == "" {
+			t.Fatalf("callback redirect = %q", location)
+		}
+
+		var user models.User
+		if err := database.DB.Where("oidc_subject = ?", "user-123").First(&user).Error; err != nil {
+			t.Fatalf("user not created: %v", err)
+		}
+		if user.Username != "alice" || user.Role != models.RoleAdmin {
+			t.Errorf("user = %s (%s), want alice (admin)", user.Username, user.Role)
+		}
+		var sessions int64
+		database.DB.Model(&models.Session{}).Where("user_id = ?", user.ID).Count(&sessions)
+		if sessions != 1 {
+			t.Errorf("sessions = %d, want 1", sessions)
+		}
+	})
+
+	t.Run("reuses bound user and syncs role", func(t *testing.T) {
+		issuer.setClaims(jwt.MapClaims{"sub": "user-123", "preferred_username": "renamed", "groups": []string{"staff"}})
+		state, cookie := oidcLogin(t, r, issuer)
+		if msg := oidcError(oidcCallback(t, r, testAuthCode, state, cookie)); msg != "" {
+			t.Fatalf("oidc_error = %q", msg)
+		}
+
+		var users []models.User
+		database.DB.Where("oidc_subject = ?", "user-123").Find(&users)
+		if len(users) != 1 || users[0].Username != "alice" || users[0].Role != models.RoleUser {
+			t.Errorf("users = %+v, want alice demoted to user", users)
+		}
+	})
+
+	t.Run("rejects local username", func(t *testing.T) {
+		issuer.setClaims(jwt.MapClaims{"sub": "user-456", "preferred_username": "admin"})
+		state, cookie := oidcLogin(t, r, issuer)
+		if msg := oidcError(oidcCallback(t, r, testAuthCode, state, cookie)); !strings.Contains(msg, "local account") {
+			t.Errorf("oidc_error = %q", msg)
+		}
+	})
+
+	t.Run("rejects state mismatch", func(t *testing.T) {
+		issuer.setClaims(jwt.MapClaims{"sub": "user-123"})
+		_, cookie := oidcLogin(t, r, issuer)
+		if msg := oidcError(oidcCallback(t, r, testAuthCode, "forged", cookie)); msg != "Invalid or expired OIDC login state" {
+			t.Errorf("oidc_error = %q", msg)
+		}
+	})
+
+	t.Run("rejects missing cookie", func(t *testing.T) {
+		state, _ := oidcLogin(t, r, issuer)
+		if msg := oidcError(oidcCallback(t, r, testAuthCode, state, nil)); msg != "Invalid or expired OIDC login state" {
+			t.Errorf("oidc_error = %q", msg)
+		}
+	})
+
+	t.Run("rejects invalid code", func(t *testing.T) {
+		state, cookie := oidcLogin(t, r, issuer)
+		if msg := oidcError(oidcCallback(t, r, "bad-code", state, cookie)); msg != "OIDC login failed" {
+			t.Errorf("oidc_error = %q", msg)
+		}
+	})
+
+	t.Run("rejects nonce mismatch", func(t *testing.T) {
+		state, cookie := oidcLogin(t, r, issuer)
+		issuer.mu.Lock()
+		issuer.nonce = "replayed"
+		issuer.mu.Unlock()
+		if msg := oidcError(oidcCallback(t, r, testAuthCode, state, cookie)); msg != "OIDC login failed" {
+			t.Errorf("oidc_error = %q", msg)
+		}
+	})
+
+	t.Run("rejects wrong audience", func(t *testing.T) {
+		issuer.mu.Lock()
+		issuer.audience = "another-client"
+		issuer.mu.Unlock()
+		defer func() {
+			issuer.mu.Lock()
+			issuer.audience = testClientID
+			issuer.mu.Unlock()
+		}()
+
+		state, cookie := oidcLogin(t, r, issuer)
+		if msg := oidcError(oidcCallback(t, r, testAuthCode, state, cookie)); msg != "OIDC login failed" {
+			t.Errorf("oidc_error = %q", msg)
+		}
+	})
+}
@@ -0,0 +1,147 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"tally/database"
+	"tally/models"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type SnoozeReminderRequest struct {
+	Days int `json:"days" binding:"required,min=1,max=365"`
+}
+
+// findReminderAck 获取资源当前周期的提醒状态，不存在时返回未保存的新记录
+func findReminderAck(resource *models.Resource) models.ReminderAck {
+	ack := models.ReminderAck{ResourceID: resource.ID, ExpireAt: resource.ExpireAt.Unix()}
+	database.DB.Where("resource_id = ? AND expire_at = ?", ack.ResourceID, ack.ExpireAt).First(&ack)
+	return ack
+}
+
+// updateReminderAck 加载资源并修改其当前周期的提醒状态，
+// afterSave 不为 nil 时与保存在同一事务中执行
+func updateReminderAck(c *gin.Context, update func(ack *models.ReminderAck), afterSave func(tx *gorm.DB, ack *models.ReminderAck) error) {
+	id := c.Param("id")
+	workspaceID := c.MustGet("workspace_id").(uint)
+
+	var resource models.Resource
+	if err := database.DB.Where("workspace_id = ?", workspaceID).First(&resource, id).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Resource not found"})
+		return
+	}
+
+	ack := findReminderAck(&resource)
+	update(&ack)
+
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(&ack).Error; err != nil {
+			return err
+		}
+		if afterSave != nil {
+			return afterSave(tx, &ack)
+		}
+		return nil
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update reminder state"})
+		return
+	}
+
+	c.JSON(http.StatusOK, ack.ToResponse())
+}
+
+// GetReminderState 获取资源当前周期的提醒状态
+func GetReminderState(c *gin.Context) {
+	id := c.Param("id")
+	workspaceID := c.MustGet("workspace_id").(uint)
+
+	var resource models.Resource
+	if err := database.DB.Where("workspace_id = ?", workspaceID).First(&resource, id).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Resource not found"})
+		return
+	}
+
+	ack := findReminderAck(&resource)
+	c.JSON(http.StatusOK, ack.ToResponse())
+}
+
+// AcknowledgeReminder 确认提醒，本周期内不再提醒，续约后自动重置
+func AcknowledgeReminder(c *gin.Context) {
+	userID := uint(c.MustGet("user_id").(float64))
+
+	updateReminderAck(c, func(ack *models.ReminderAck) {
+		now := time.Now()
+		ack.AcknowledgedAt = &now
+		ack.AcknowledgedBy = userID
+	}, func(tx *gorm.DB, ack *models.ReminderAck) error {
+		// 确认保存成功后停止本周期的升级
+		return stopEscalations(tx, models.EscalationAcknowledged, "resource_id = ? AND expire_at = ?", ack.ResourceID, ack.ExpireAt)
+	})
+}
+
+// UnacknowledgeReminder 取消确认，恢复提醒
+func UnacknowledgeReminder(c *gin.Context) {
+	updateReminderAck(c, func(ack *models.ReminderAck) {
+		ack.AcknowledgedAt = nil
+		ack.AcknowledgedBy = 0
+	}, nil)
+}
+
+// SnoozeReminder 暂停提醒 N 天，不修改到期时间
+func SnoozeReminder(c *gin.Context) {
+	var req SnoozeReminderRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request, days must be between 1 and 365"})
+		return
+	}
+
+	updateReminderAck(c, func(ack *models.ReminderAck) {
+		until := time.Now().AddDate(0, 0, req.Days)
+		ack.SnoozedUntil = &until
+	}, nil)
+}
+
+// UnsnoozeReminder 取消暂停，恢复提醒
+func UnsnoozeReminder(c *gin.Context) {
+	updateReminderAck(c, func(ack *models.ReminderAck) {
+		ack.SnoozedUntil = nil
+	}, nil)
+}
+
+// attachReminderStates 为资源列表填充当前周期的确认 / 暂停状态
+func attachReminderStates(responses []models.ResourceResponse) {
+	if len(responses) == 0 {
+		return
+	}
+
+	ids := make([]uint, len(responses))
+	for i, r := range responses {
+		ids[i] = r.ID
+	}
+
+	var acks []models.ReminderAck
+	database.DB.Where("resource_id IN ?", ids).Find(&acks)
+
+	// 只有与当前到期时间一致的记录才属于当前周期
+	type cycle struct {
+		resourceID uint
+		expireAt   int64
+	}
+	states := make(map[cycle]models.ReminderStateResponse, len(acks))
+	for i := range acks {
+		states[cycle{acks[i].ResourceID, acks[i].ExpireAt}] = acks[i].ToResponse()
+	}
+
+	for i := range responses {
+		state, ok := states[cycle{responses[i].ID, responses[i].ExpireAt}]
+		if !ok {
+			continue
+		}
+		responses[i].Acknowledged = state.Acknowledged
+		responses[i].SnoozedUntil = state.SnoozedUntil
+	}
+}
@@ -0,0 +1,95 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"tally/database"
+	"tally/models"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// errRenewalChanged 撤销时资源的到期时间或续约记录已被其他请求修改
+var errRenewalChanged = errors.New("renewal changed")
+
+// GetRenewals 获取资源的续约记录，按时间倒序
+func GetRenewals(c *gin.Context) {
+	workspaceID := c.MustGet("workspace_id").(uint)
+
+	var resource models.Resource
+	if err := database.DB.Where("workspace_id = ?", workspaceID).First(&resource, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Resource not found"})
+		return
+	}
+
+	var renewals []models.Renewal
+	if err := database.DB.Where("resource_id = ?", resource.ID).Order("id DESC").Limit(200).Find(&renewals).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch renewals"})
+		return
+	}
+
+	responses := make([]models.RenewalResponse, len(renewals))
+	for i := range renewals {
+		responses[i] = renewals[i].ToResponse()
+	}
+	c.JSON(http.StatusOK, responses)
+}
+
+// UndoRenewal 撤销资源最近一次未撤销的续约，恢复续约前的到期时间
+func UndoRenewal(c *gin.Context) {
+	workspaceID := c.MustGet("workspace_id").(uint)
+
+	var resource models.Resource
+	if err := database.DB.Where("workspace_id = ?", workspaceID).First(&resource, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Resource not found"})
+		return
+	}
+
+	var renewal models.Renewal
+	if err := database.DB.Where("resource_id = ? AND undone_at IS NULL", resource.ID).
+		Order("id DESC").First(&renewal).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "No renewal to undo"})
+		return
+	}
+
+	// 续约后到期时间又被修改过时拒绝撤销，避免覆盖之后的修改
+	if resource.ExpireAt.Unix() != renewal.NewExpireAt.Unix() {
+		c.JSON(http.StatusConflict, gin.H{"error": "Expiry date has changed since the last renewal"})
+		return
+	}
+
+	// 在事务中按条件更新，期间有并发的续约、自动续费或撤销时不覆盖
+	before := resource
+	if err := database.DB.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&resource).Where("expire_at = ?", renewal.NewExpireAt).Update("expire_at", renewal.OldExpireAt)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return errRenewalChanged
+		}
+		result = tx.Model(&renewal).Where("undone_at IS NULL").Update("undone_at", time.Now())
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return errRenewalChanged
+		}
+		return nil
+	}); err != nil {
+		if errors.Is(err, errRenewalChanged) {
+			c.JSON(http.StatusConflict, gin.H{"error": "Expiry date has changed since the last renewal"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to undo renewal"})
+		return
+	}
+	resource.ExpireAt = renewal.OldExpireAt
+
+	recordAudit(c, models.AuditResourceUndo, models.AuditTargetResource, resource.ID, workspaceID, before, resource)
+
+	c.JSON(http.StatusOK, resource.ToResponse())
+}
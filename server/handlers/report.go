@@ -0,0 +1,215 @@
+package handlers
+
+import (
+	"net/http"
+	"sort"
+	"time"
+
+	"tally/config"
+	"tally/database"
+	"tally/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// reportMonths 支出报表统计和预测的月数
+const reportMonths = 12
+
+// SpendingMonth 单月支出
+type SpendingMonth struct {
+	Month      string             `json:"month"` // 2006-01
+	Total      float64            `json:"total"` // 换算为基准货币
+	ByCurrency map[string]float64 `json:"by_currency"`
+}
+
+// SpendingSummary 一段时间内的支出，Total、ByGroup 和每月 Total 换算为基准货币，缺少汇率的金额只计入 ByCurrency
+type SpendingSummary struct {
+	From       int64              `json:"from"` // Unix 时间戳
+	To         int64              `json:"to"`
+	Total      float64            `json:"total"`
+	ByCurrency map[string]float64 `json:"by_currency"`
+	ByGroup    map[string]float64 `json:"by_group"`
+	ByMonth    []SpendingMonth    `json:"by_month"`
+}
+
+// SpendingReport 支出报表：过去 12 个月的实际支出（按续约记录）与未来 12 个月的预测支出（按到期时间与计费周期）
+type SpendingReport struct {
+	BaseCurrency string          `json:"base_currency"`
+	Past         SpendingSummary `json:"past"`
+	Forecast     SpendingSummary `json:"forecast"`
+	MissingRates []string        `json:"missing_rates"` // 没有汇率、未计入换算合计的货币
+}
+
+// GetSpendingReport 获取当前工作区的支出报表，可通过 base 参数指定换算的货币
+func GetSpendingReport(c *gin.Context) {
+	workspaceID := c.MustGet("workspace_id").(uint)
+
+	base := config.GetBaseCurrency()
+	if v := c.Query("base"); v != "" {
+		base = models.NormalizeCurrency(v)
+		if !models.ValidCurrency(base) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Base currency must be an ISO 4217 code"})
+			return
+		}
+	}
+
+	converter, err := newCurrencyConverter(base)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch exchange rates"})
+		return
+	}
+
+	now := time.Now()
+	thisMonth := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location())
+
+	// 过去 12 个月（含本月）的续约支出，不含已撤销的续约
+	past := newSpendingSummary(models.AddMonths(thisMonth, 1-reportMonths), now)
+	var renewals []models.Renewal
+	if err := database.DB.
+		Where("workspace_id = ? AND cost IS NOT NULL AND undone_at IS NULL AND created_at >= ?", workspaceID, time.Unix(past.From, 0)).
+		Find(&renewals).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch renewals"})
+		return
+	}
+	for _, r := range renewals {
+		past.add(converter, r.CreatedAt, r.GroupName, r.Currency, *r.Cost)
+	}
+
+	// 未来 12 个月（含本月剩余时间）按计费周期到期续费的预测支出
+	forecast := newSpendingSummary(now, models.AddMonths(thisMonth, reportMonths))
+	var resources []models.Resource
+	if err := database.DB.Where("workspace_id = ? AND price IS NOT NULL", workspaceID).Find(&resources).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch resources"})
+		return
+	}
+	end := time.Unix(forecast.To, 0)
+	for _, r := range resources {
+		for n := 0; ; n++ {
+			at, ok := models.NextBillingDate(r.ExpireAt, r.BillingCycle, r.BillingDays, n)
+			if !ok || !at.Before(end) {
+				break
+			}
+			if !at.Before(now) {
+				forecast.add(converter, at, r.GroupName, r.Currency, *r.Price)
+			}
+		}
+	}
+
+	past.round()
+	forecast.round()
+	c.JSON(http.StatusOK, SpendingReport{
+		BaseCurrency: base,
+		Past:         *past,
+		Forecast:     *forecast,
+		MissingRates: converter.missingCurrencies(),
+	})
+}
+
+func newSpendingSummary(from, to time.Time) *SpendingSummary {
+	s := &SpendingSummary{
+		From:       from.Unix(),
+		To:         to.Unix(),
+		ByCurrency: map[string]float64{},
+		ByGroup:    map[string]float64{},
+		ByMonth:    make([]SpendingMonth, reportMonths),
+	}
+	start := time.Date(from.Year(), from.Month(), 1, 0, 0, 0, 0, from.Location())
+	for i := range s.ByMonth {
+		s.ByMonth[i] = SpendingMonth{
+			Month:      models.AddMonths(start, i).Format("2006-01"),
+			ByCurrency: map[string]float64{},
+		}
+	}
+	return s
+}
+
+// add 计入一笔支出，按发生时间所在月份归类
+func (s *SpendingSummary) add(converter *currencyConverter, at time.Time, group, currency string, amount float64) {
+	at = at.In(time.Local)
+	month := at.Format("2006-01")
+	var bucket *SpendingMonth
+	for i := range s.ByMonth {
+		if s.ByMonth[i].Month == month {
+			bucket = &s.ByMonth[i]
+		}
+	}
+	if bucket == nil {
+		return
+	}
+
+	s.ByCurrency[currency] += amount
+	bucket.ByCurrency[currency] += amount
+	if converted, ok := converter.convert(currency, amount); ok {
+		s.Total += converted
+		s.ByGroup[group] += converted
+		bucket.Total += converted
+	}
+}
+
+func (s *SpendingSummary) round() {
+	s.Total = models.RoundMoney(s.Total)
+	roundAll(s.ByCurrency)
+	roundAll(s.ByGroup)
+	for i := range s.ByMonth {
+		s.ByMonth[i].Total = models.RoundMoney(s.ByMonth[i].Total)
+		roundAll(s.ByMonth[i].ByCurrency)
+	}
+}
+
+func roundAll(amounts map[string]float64) {
+	for k, v := range amounts {
+		amounts[k] = models.RoundMoney(v)
+	}
+}
+
+// currencyConverter 按汇率表换算货币，汇率表中的汇率均相对于 BASE_CURRENCY，换算为其他货币时通过交叉汇率计算
+type currencyConverter struct {
+	rates   map[string]float64
+	target  string
+	missing map[string]bool
+}
+
+func newCurrencyConverter(target string) (*currencyConverter, error) {
+	var rates []models.ExchangeRate
+	if err := database.DB.Find(&rates).Error; err != nil {
+		return nil, err
+	}
+
+	converter := &currencyConverter{
+		rates:   map[string]float64{config.GetBaseCurrency(): 1},
+		target:  target,
+		missing: map[string]bool{},
+	}
+	for _, r := range rates {
+		converter.rates[r.Currency] = r.Rate
+	}
+	if _, ok := converter.rates[target]; !ok {
+		converter.missing[target] = true
+	}
+	return converter, nil
+}
+
+// convert 将金额换算为目标货币，缺少汇率时返回 false 并记录该货币
+func (cc *currencyConverter) convert(currency string, amount float64) (float64, bool) {
+	if currency == cc.target {
+		return amount, true
+	}
+	from, ok := cc.rates[currency]
+	to, targetOK := cc.rates[cc.target]
+	if !ok {
+		cc.missing[currency] = true
+	}
+	if !ok || !targetOK {
+		return 0, false
+	}
+	return amount * from / to, true
+}
+
+func (cc *currencyConverter) missingCurrencies() []string {
+	missing := make([]string, 0, len(cc.missing))
+	for currency := range cc.missing {
+		missing = append(missing, currency)
+	}
+	sort.Strings(missing)
+	return missing
+}
@@ -1,37 +1,137 @@
 package handlers
 
 import (
+	"errors"
+	"fmt"
 	"net/http"
 	"sort"
 	"time"
 
 	"tally/database"
 	"tally/models"
+	"tally/notifier"
 
 	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
 )
 
 type CreateResourceRequest struct {
 	Name      string `json:"name" binding:"required"`
 	GroupName string `json:"group"`
 	ExpireAt  int64  `json:"expire_at" binding:"required"` // Unix 时间戳
+
+	// 提醒策略，不提供时继承分组设置或全局默认
+	ReminderDays      []int `json:"reminder_days"`
+	RepeatAfterExpiry *int  `json:"repeat_after_expiry"`
+
+	EscalationPolicyID *uint `json:"escalation_policy_id"`
+
+	// 费用，custom 计费周期需提供 billing_days
+	Price        *float64 `json:"price"`
+	Currency     string   `json:"currency"`
+	BillingCycle string   `json:"billing_cycle"`
+	BillingDays  int      `json:"billing_days"`
+	AutoRenew    bool     `json:"auto_renew"`
 }
 
+// errExpireAtChanged 续约时资源的到期时间已被其他请求修改
+var errExpireAtChanged = errors.New("expire_at changed")
+
+// 续期的计算基准
+const (
+	RenewBaseExpiry = "expiry" // 从当前到期日开始，已过期时从今天开始（默认）
+	RenewBaseToday  = "today"  // 始终从今天开始
+)
+
 type RenewRequest struct {
 	Days     *int   `json:"days"`
-	ExpireAt *int64 `json:"expire_at"` // Unix 时间戳
+	Months   *int   `json:"months"` // 按日历月计算，目标月份没有对应日期时取月末
+	Years    *int   `json:"years"`
+	Base     string `json:"base"`      // expiry 或 today，对 days、months、years 生效，不能与 expire_at 同时使用
+	ExpireAt *int64 `json:"expire_at"` // Unix 时间戳，优先于其他参数
+
+	// 续约记录，不提供 cost 时按资源价格估算，currency 默认为资源的货币
+	Cost     *float64 `json:"cost"`
+	Currency string   `json:"currency"`
+	Note     string   `json:"note"`
 }
 
 type UpdateResourceRequest struct {
 	Name      *string `json:"name"`
 	GroupName *string `json:"group"`
 	ExpireAt  *int64  `json:"expire_at"` // Unix 时间戳
+
+	ReminderDays      *[]int `json:"reminder_days"`
+	RepeatAfterExpiry *int   `json:"repeat_after_expiry"`
+	ResetReminder     bool   `json:"reset_reminder"` // 为 true 时清除资源自身的提醒策略，恢复继承
+
+	EscalationPolicyID *uint `json:"escalation_policy_id"` // 为 0 时取消升级
+
+	Price        *float64 `json:"price"`
+	Currency     *string  `json:"currency"`
+	BillingCycle *string  `json:"billing_cycle"`
+	BillingDays  *int     `json:"billing_days"`
+	AutoRenew    *bool    `json:"auto_renew"`
+	ResetBilling bool     `json:"reset_billing"` // 为 true 时清除费用设置
+}
+
+const (
+	maxReminderDays      = 3650 // 单个提醒天数上限
+	maxReminderCount     = 20   // 提醒次数上限
+	maxRepeatAfterExpiry = 365  // 过期重复间隔上限
+	maxRenewalNoteLength = 500  // 续约备注长度上限
+)
+
+// validateReminderPolicy 校验提醒策略参数，nil 表示未提供
+func validateReminderPolicy(days []int, repeatAfterExpiry *int) error {
+	if len(days) > maxReminderCount {
+		return fmt.Errorf("reminder_days supports at most %d entries", maxReminderCount)
+	}
+	for _, d := range days {
+		if d < 0 || d > maxReminderDays {
+			return fmt.Errorf("reminder_days must be between 0 and %d", maxReminderDays)
+		}
+	}
+	if repeatAfterExpiry != nil && (*repeatAfterExpiry < 0 || *repeatAfterExpiry > maxRepeatAfterExpiry) {
+		return fmt.Errorf("repeat_after_expiry must be between 0 and %d", maxRepeatAfterExpiry)
+	}
+	return nil
 }
 
-// GetResources 获取所有资源
+// validateBilling 校验资源的费用设置，并规范化货币代码和计费天数
+func validateBilling(r *models.Resource) error {
+	r.Currency = models.NormalizeCurrency(r.Currency)
+	if r.Price != nil && *r.Price < 0 {
+		return fmt.Errorf("price must not be negative")
+	}
+	if r.Price != nil && r.Currency == "" {
+		return fmt.Errorf("currency is required when price is set")
+	}
+	if r.Currency != "" && !models.ValidCurrency(r.Currency) {
+		return fmt.Errorf("currency must be an ISO 4217 code")
+	}
+	if !models.ValidBillingCycle(r.BillingCycle) {
+		return fmt.Errorf("billing_cycle must be one of monthly, quarterly, yearly, one_off, custom")
+	}
+	if r.BillingCycle == models.BillingCustom {
+		if r.BillingDays < 1 || r.BillingDays > models.MaxBillingDays {
+			return fmt.Errorf("billing_days must be between 1 and %d", models.MaxBillingDays)
+		}
+	} else {
+		r.BillingDays = 0
+	}
+	if r.AutoRenew && !models.Recurring(r.BillingCycle) {
+		return fmt.Errorf("auto_renew requires a recurring billing_cycle")
+	}
+	return nil
+}
+
+// GetResources 获取当前工作区的所有资源
 func GetResources(c *gin.Context) {
+	workspaceID := c.MustGet("workspace_id").(uint)
+
 	var resources []models.Resource
-	if err := database.DB.Find(&resources).Error; err != nil {
+	if err := database.DB.Where("workspace_id = ?", workspaceID).Find(&resources).Error; err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch resources"})
 		return
 	}
@@ -46,22 +146,52 @@ func GetResources(c *gin.Context) {
 	sort.Slice(responses, func(i, j int) bool {
 		return responses[i].ExpireAt < responses[j].ExpireAt
 	})
+	attachReminderStates(responses)
 
 	c.JSON(http.StatusOK, responses)
 }
 
 // CreateResource 创建新资源
 func CreateResource(c *gin.Context) {
+	userID := uint(c.MustGet("user_id").(float64))
+	workspaceID := c.MustGet("workspace_id").(uint)
+
 	var req CreateResourceRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request: " + err.Error()})
 		return
 	}
+	if err := validateReminderPolicy(req.ReminderDays, req.RepeatAfterExpiry); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if req.EscalationPolicyID != nil {
+		if err := checkEscalationPolicyID(userID, *req.EscalationPolicyID); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
 
 	resource := models.Resource{
-		Name:      req.Name,
-		GroupName: req.GroupName,
-		ExpireAt:  time.Unix(req.ExpireAt, 0),
+		UserID:            userID,
+		WorkspaceID:       workspaceID,
+		Name:              req.Name,
+		GroupName:         req.GroupName,
+		ExpireAt:          time.Unix(req.ExpireAt, 0),
+		ReminderDays:      models.NormalizeReminderDays(req.ReminderDays),
+		RepeatAfterExpiry: req.RepeatAfterExpiry,
+
+		EscalationPolicyID: req.EscalationPolicyID,
+
+		Price:        req.Price,
+		Currency:     req.Currency,
+		BillingCycle: req.BillingCycle,
+		BillingDays:  req.BillingDays,
+		AutoRenew:    req.AutoRenew,
+	}
+	if err := validateBilling(&resource); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
 	}
 
 	if err := database.DB.Create(&resource).Error; err != nil {
@@ -69,12 +199,17 @@ func CreateResource(c *gin.Context) {
 		return
 	}
 
+	recordAudit(c, models.AuditResourceCreate, models.AuditTargetResource, resource.ID, workspaceID, nil, resource)
+	notifier.DispatchEvent(notifier.EventCreated, resource)
+
 	c.JSON(http.StatusCreated, resource.ToResponse())
 }
 
 // RenewResource 续约资源
 func RenewResource(c *gin.Context) {
 	id := c.Param("id")
+	userID := uint(c.MustGet("user_id").(float64))
+	workspaceID := c.MustGet("workspace_id").(uint)
 
 	var req RenewRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
@@ -82,54 +217,178 @@ func RenewResource(c *gin.Context) {
 		return
 	}
 
-	// 必须提供 days 或 expire_at 其中之一
-	if req.Days == nil && req.ExpireAt == nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Must provide either 'days' or 'expire_at'"})
+	// 必须提供 days、months、years 或 expire_at
+	if req.Days == nil && req.Months == nil && req.Years == nil && req.ExpireAt == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Must provide 'days', 'months', 'years' or 'expire_at'"})
+		return
+	}
+	if (req.Days != nil && *req.Days < 0) || (req.Months != nil && *req.Months < 0) || (req.Years != nil && *req.Years < 0) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "days, months and years must not be negative"})
+		return
+	}
+	// expire_at 为绝对时间，不使用计算基准
+	if req.Base != "" && req.ExpireAt != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "base cannot be used with expire_at"})
+		return
+	}
+	if req.Base == "" {
+		req.Base = RenewBaseExpiry
+	}
+	if req.Base != RenewBaseExpiry && req.Base != RenewBaseToday {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "base must be 'expiry' or 'today'"})
+		return
+	}
+
+	if req.Cost != nil && *req.Cost < 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "cost must not be negative"})
+		return
+	}
+	if len(req.Note) > maxRenewalNoteLength {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("note must be at most %d characters", maxRenewalNoteLength)})
 		return
 	}
 
 	var resource models.Resource
-	if err := database.DB.First(&resource, id).Error; err != nil {
+	if err := database.DB.Where("workspace_id = ?", workspaceID).First(&resource, id).Error; err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": "Resource not found"})
 		return
 	}
 
-	// 更新到期时间
+	// 续约记录的费用，未提供时按资源价格估算
+	currency := resource.Currency
+	if req.Cost != nil {
+		if req.Currency != "" {
+			currency = models.NormalizeCurrency(req.Currency)
+		}
+		if currency == "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "currency is required when cost is set"})
+			return
+		}
+		if !models.ValidCurrency(currency) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "currency must be an ISO 4217 code"})
+			return
+		}
+	}
+
+	before := resource
+	previousExpireAt := resource.ExpireAt
+
+	// 如果已过期或指定从今天开始，从今天开始计算；否则从当前到期日开始
+	baseTime := resource.ExpireAt
+	if req.Base == RenewBaseToday || baseTime.Before(time.Now()) {
+		baseTime = time.Now()
+	}
+
+	// 更新到期时间，先按日历加年月再加天数
 	if req.ExpireAt != nil {
 		resource.ExpireAt = time.Unix(*req.ExpireAt, 0)
-	} else if req.Days != nil {
-		// 如果已过期，从今天开始计算；否则从当前到期日开始
-		baseTime := resource.ExpireAt
-		if baseTime.Before(time.Now()) {
-			baseTime = time.Now()
+	} else {
+		months := 0
+		if req.Years != nil {
+			months += *req.Years * 12
 		}
-		resource.ExpireAt = baseTime.AddDate(0, 0, *req.Days)
+		if req.Months != nil {
+			months += *req.Months
+		}
+		days := 0
+		if req.Days != nil {
+			days = *req.Days
+		}
+		resource.ExpireAt = models.AddMonths(baseTime, months).AddDate(0, 0, days)
+	}
+
+	// 到期时间未变化时不写续约记录和审计事件
+	if resource.ExpireAt.Unix() == previousExpireAt.Unix() {
+		c.JSON(http.StatusOK, resource.ToResponse())
+		return
+	}
+
+	renewal := models.Renewal{
+		ResourceID:  resource.ID,
+		WorkspaceID: workspaceID,
+		UserID:      userID,
+		Username:    c.GetString("username"),
+		GroupName:   resource.GroupName,
+		OldExpireAt: previousExpireAt,
+		NewExpireAt: resource.ExpireAt,
+		DaysAdded:   models.RenewalDays(previousExpireAt, resource.ExpireAt),
+		Cost:        req.Cost,
+		Currency:    currency,
+		Note:        req.Note,
+	}
+	if renewal.Cost == nil {
+		renewal.Cost = models.EstimateRenewalCost(&resource, baseTime, resource.ExpireAt)
 	}
 
-	if err := database.DB.Save(&resource).Error; err != nil {
+	// 到期时间、续约记录和提醒状态在同一事务中写入，到期时间被并发修改时放弃本次续约
+	if err := database.DB.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&resource).Where("expire_at = ?", previousExpireAt).Update("expire_at", resource.ExpireAt)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return errExpireAtChanged
+		}
+
+		// 到期时间后移即进入新的提醒周期，清除确认与暂停状态
+		if resource.ExpireAt.After(previousExpireAt) {
+			if err := tx.Where("resource_id = ?", resource.ID).Delete(&models.ReminderAck{}).Error; err != nil {
+				return err
+			}
+			if err := stopEscalations(tx, models.EscalationResolved, "resource_id = ?", resource.ID); err != nil {
+				return err
+			}
+		}
+		return tx.Create(&renewal).Error
+	}); err != nil {
+		if errors.Is(err, errExpireAtChanged) {
+			c.JSON(http.StatusConflict, gin.H{"error": "Expiry date has changed, please reload and try again"})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to renew resource"})
 		return
 	}
 
+	recordAudit(c, models.AuditResourceRenew, models.AuditTargetResource, resource.ID, workspaceID, before, resource)
+	notifier.DispatchEvent(notifier.EventRenewed, resource)
+
 	c.JSON(http.StatusOK, resource.ToResponse())
 }
 
 // UpdateResource 更新资源信息
 func UpdateResource(c *gin.Context) {
 	id := c.Param("id")
+	userID := uint(c.MustGet("user_id").(float64))
+	workspaceID := c.MustGet("workspace_id").(uint)
 
 	var req UpdateResourceRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request"})
 		return
 	}
+	var reminderDays []int
+	if req.ReminderDays != nil {
+		reminderDays = *req.ReminderDays
+	}
+	if err := validateReminderPolicy(reminderDays, req.RepeatAfterExpiry); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if req.EscalationPolicyID != nil && *req.EscalationPolicyID != 0 {
+		if err := checkEscalationPolicyID(userID, *req.EscalationPolicyID); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
 
 	var resource models.Resource
-	if err := database.DB.First(&resource, id).Error; err != nil {
+	if err := database.DB.Where("workspace_id = ?", workspaceID).First(&resource, id).Error; err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": "Resource not found"})
 		return
 	}
 
+	before := resource
+
 	// 更新提供的字段
 	if req.Name != nil {
 		resource.Name = *req.Name
@@ -140,32 +399,116 @@ func UpdateResource(c *gin.Context) {
 	if req.ExpireAt != nil {
 		resource.ExpireAt = time.Unix(*req.ExpireAt, 0)
 	}
+	if req.ResetReminder {
+		resource.ReminderDays = nil
+		resource.RepeatAfterExpiry = nil
+	}
+	if req.ReminderDays != nil {
+		resource.ReminderDays = models.NormalizeReminderDays(*req.ReminderDays)
+	}
+	if req.RepeatAfterExpiry != nil {
+		resource.RepeatAfterExpiry = req.RepeatAfterExpiry
+	}
+	if req.EscalationPolicyID != nil {
+		if *req.EscalationPolicyID == 0 {
+			resource.EscalationPolicyID = nil
+		} else {
+			resource.EscalationPolicyID = req.EscalationPolicyID
+		}
+	}
+	if req.ResetBilling {
+		resource.Price = nil
+		resource.Currency = ""
+		resource.BillingCycle = ""
+		resource.BillingDays = 0
+		resource.AutoRenew = false
+	}
+	if req.Price != nil {
+		resource.Price = req.Price
+	}
+	if req.Currency != nil {
+		resource.Currency = *req.Currency
+	}
+	if req.BillingCycle != nil {
+		resource.BillingCycle = *req.BillingCycle
+	}
+	if req.BillingDays != nil {
+		resource.BillingDays = *req.BillingDays
+	}
+	if req.AutoRenew != nil {
+		resource.AutoRenew = *req.AutoRenew
+	}
+	if err := validateBilling(&resource); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
 
-	if err := database.DB.Save(&resource).Error; err != nil {
+	// 取消升级策略时同一事务中结束进行中的升级
+	if err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(&resource).Error; err != nil {
+			return err
+		}
+		if resource.EscalationPolicyID == nil {
+			return stopEscalations(tx, models.EscalationResolved, "resource_id = ?", resource.ID)
+		}
+		return nil
+	}); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update resource"})
 		return
 	}
 
+	recordAudit(c, models.AuditResourceUpdate, models.AuditTargetResource, resource.ID, workspaceID, before, resource)
+
 	c.JSON(http.StatusOK, resource.ToResponse())
 }
 
 // DeleteResource 删除资源
 func DeleteResource(c *gin.Context) {
 	id := c.Param("id")
+	workspaceID := c.MustGet("workspace_id").(uint)
 
-	if err := database.DB.Delete(&models.Resource{}, id).Error; err != nil {
+	var resource models.Resource
+	if err := database.DB.Where("workspace_id = ?", workspaceID).First(&resource, id).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Resource not found"})
+		return
+	}
+
+	if err := database.DB.Delete(&resource).Error; err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete resource"})
 		return
 	}
 
+	// 清理提醒发送记录与确认状态
+	deleteResourceRecords(database.DB, []uint{resource.ID})
+
+	recordAudit(c, models.AuditResourceDelete, models.AuditTargetResource, resource.ID, workspaceID, resource, nil)
+	notifier.DispatchEvent(notifier.EventDeleted, resource)
+
 	c.JSON(http.StatusOK, gin.H{"message": "Resource deleted"})
 }
 
-// GetGroups 获取所有唯一分组名
+// deleteResourceRecords 删除资源的提醒发送记录、确认状态与升级进度，resourceIDs 为 ID 列表或子查询
+func deleteResourceRecords(tx *gorm.DB, resourceIDs interface{}) error {
+	for _, model := range []interface{}{
+		&models.NotificationLog{},
+		&models.ReminderAck{},
+		&models.EscalationState{},
+	} {
+		if err := tx.Where("resource_id IN (?)", resourceIDs).Delete(model).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// GetGroups 获取当前工作区资源的所有唯一分组名
 func GetGroups(c *gin.Context) {
+	workspaceID := c.MustGet("workspace_id").(uint)
+
 	var groups []string
 	if err := database.DB.Model(&models.Resource{}).
 		Distinct().
+		Where("workspace_id = ?", workspaceID).
 		Where("group_name != '' AND group_name IS NOT NULL").
 		Pluck("group_name", &groups).Error; err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch groups: " + err.Error()})
@@ -184,6 +527,15 @@ type BackupResource struct {
 	GroupName string `json:"group"`
 	ExpireAt  int64  `json:"expire_at"`
 	CreatedAt int64  `json:"created_at"`
+
+	ReminderDays      models.IntList `json:"reminder_days"`
+	RepeatAfterExpiry *int           `json:"repeat_after_expiry,omitempty"`
+
+	Price        *float64 `json:"price,omitempty"`
+	Currency     string   `json:"currency,omitempty"`
+	BillingCycle string   `json:"billing_cycle,omitempty"`
+	BillingDays  int      `json:"billing_days,omitempty"`
+	AutoRenew    bool     `json:"auto_renew,omitempty"`
 }
 
 // BackupData 备份数据结构
@@ -193,10 +545,12 @@ type BackupData struct {
 	Resources []BackupResource `json:"resources"`
 }
 
-// ExportBackup 导出所有资源为 JSON 备份
+// ExportBackup 导出当前工作区的所有资源为 JSON 备份
 func ExportBackup(c *gin.Context) {
+	workspaceID := c.MustGet("workspace_id").(uint)
+
 	var resources []models.Resource
-	if err := database.DB.Find(&resources).Error; err != nil {
+	if err := database.DB.Where("workspace_id = ?", workspaceID).Find(&resources).Error; err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch resources"})
 		return
 	}
@@ -209,10 +563,18 @@ func ExportBackup(c *gin.Context) {
 
 	for i, r := range resources {
 		backup.Resources[i] = BackupResource{
-			Name:      r.Name,
-			GroupName: r.GroupName,
-			ExpireAt:  r.ExpireAt.Unix(),
-			CreatedAt: r.CreatedAt.Unix(),
+			Name:              r.Name,
+			GroupName:         r.GroupName,
+			ExpireAt:          r.ExpireAt.Unix(),
+			CreatedAt:         r.CreatedAt.Unix(),
+			ReminderDays:      r.ReminderDays,
+			RepeatAfterExpiry: r.RepeatAfterExpiry,
+
+			Price:        r.Price,
+			Currency:     r.Currency,
+			BillingCycle: r.BillingCycle,
+			BillingDays:  r.BillingDays,
+			AutoRenew:    r.AutoRenew,
 		}
 	}
 
@@ -225,8 +587,11 @@ type ImportBackupRequest struct {
 	Data BackupData `json:"data" binding:"required"`
 }
 
-// ImportBackup 从 JSON 备份还原资源
+// ImportBackup 从 JSON 备份还原资源到当前工作区
 func ImportBackup(c *gin.Context) {
+	userID := uint(c.MustGet("user_id").(float64))
+	workspaceID := c.MustGet("workspace_id").(uint)
+
 	var req ImportBackupRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request: " + err.Error()})
@@ -239,9 +604,54 @@ func ImportBackup(c *gin.Context) {
 		return
 	}
 
-	// 如果是覆盖模式，先删除所有现有资源
+	// 先转换并校验全部资源，避免覆盖模式下删除现有资源后才发现备份有误
+	resources := make([]models.Resource, len(req.Data.Resources))
+	for i, r := range req.Data.Resources {
+		resources[i] = models.Resource{
+			UserID:            userID,
+			WorkspaceID:       workspaceID,
+			Name:              r.Name,
+			GroupName:         r.GroupName,
+			ExpireAt:          time.Unix(r.ExpireAt, 0),
+			ReminderDays:      models.NormalizeReminderDays(r.ReminderDays),
+			RepeatAfterExpiry: r.RepeatAfterExpiry,
+
+			Price:        r.Price,
+			Currency:     r.Currency,
+			BillingCycle: r.BillingCycle,
+			BillingDays:  r.BillingDays,
+			AutoRenew:    r.AutoRenew,
+		}
+		// 如果有 created_at，使用它；否则使用当前时间
+		if r.CreatedAt > 0 {
+			resources[i].CreatedAt = time.Unix(r.CreatedAt, 0)
+		}
+		if err := validateReminderPolicy(r.ReminderDays, r.RepeatAfterExpiry); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid resource " + r.Name + ": " + err.Error()})
+			return
+		}
+		if err := validateBilling(&resources[i]); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid resource " + r.Name + ": " + err.Error()})
+			return
+		}
+	}
+
+	// 如果是覆盖模式，先删除当前工作区的所有现有资源及其提醒记录和续约记录
+	var deleted int64
 	if req.Mode == "overwrite" {
-		if err := database.DB.Exec("DELETE FROM resources").Error; err != nil {
+		if err := database.DB.Transaction(func(tx *gorm.DB) error {
+			existing := tx.Model(&models.Resource{}).Select("id").Where("workspace_id = ?", workspaceID)
+			if err := deleteResourceRecords(tx, existing); err != nil {
+				return err
+			}
+			// 续约记录随资源一起删除，不再计入支出报表
+			if err := tx.Where("workspace_id = ?", workspaceID).Delete(&models.Renewal{}).Error; err != nil {
+				return err
+			}
+			result := tx.Where("workspace_id = ?", workspaceID).Delete(&models.Resource{})
+			deleted = result.RowsAffected
+			return result.Error
+		}); err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to clear existing resources"})
 			return
 		}
@@ -249,26 +659,19 @@ func ImportBackup(c *gin.Context) {
 
 	// 导入资源
 	imported := 0
-	for _, r := range req.Data.Resources {
-		resource := models.Resource{
-			Name:      r.Name,
-			GroupName: r.GroupName,
-			ExpireAt:  time.Unix(r.ExpireAt, 0),
-		}
-		// 如果有 created_at，使用它；否则使用当前时间
-		if r.CreatedAt > 0 {
-			resource.CreatedAt = time.Unix(r.CreatedAt, 0)
-		}
-
-		if err := database.DB.Create(&resource).Error; err != nil {
+	for i := range resources {
+		resource := &resources[i]
+		if err := database.DB.Create(resource).Error; err != nil {
+			recordImportAudit(c, workspaceID, req.Mode, deleted, imported)
 			c.JSON(http.StatusInternalServerError, gin.H{
-				"error":    "Failed to import resource: " + r.Name,
+				"error":    "Failed to import resource: " + resource.Name,
 				"imported": imported,
 			})
 			return
 		}
 		imported++
 	}
+	recordImportAudit(c, workspaceID, req.Mode, deleted, imported)
 
 	c.JSON(http.StatusOK, gin.H{
 		"message":  "Backup restored successfully",
@@ -276,3 +679,12 @@ func ImportBackup(c *gin.Context) {
 		"mode":     req.Mode,
 	})
 }
+
+// recordImportAudit 记录还原备份，只记录模式和删除、导入的数量，不逐条记录资源
+func recordImportAudit(c *gin.Context, workspaceID uint, mode string, deleted int64, imported int) {
+	recordAudit(c, models.AuditResourceImport, models.AuditTargetResource, 0, workspaceID, nil, gin.H{
+		"mode":     mode,
+		"deleted":  deleted,
+		"imported": imported,
+	})
+}
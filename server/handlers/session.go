@@ -0,0 +1,234 @@
+package handlers
+
+import (
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"tally/config"
+	"tally/database"
+	"tally/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+	"gorm.io/gorm"
+)
+
+// refreshReuseGrace 刷新令牌更换后的宽限期，期间重复提交旧令牌（如多个标签页同时刷新）只会被拒绝，
+// 超过宽限期仍使用旧令牌视为令牌被盗用，撤销整个会话
+const refreshReuseGrace = time.Minute
+
+// maxUserAgentLength 会话记录的 User-Agent 最大长度
+const maxUserAgentLength = 255
+
+type RefreshTokenRequest struct {
+	RefreshToken string `json:"refresh_token" binding:"required"`
+}
+
+// createSession 创建会话并签发 access token 和刷新令牌
+func createSession(c *gin.Context, user *models.User) (*LoginResponse, error) {
+	now := time.Now()
+	refreshToken, err := models.GenerateRefreshToken()
+	if err != nil {
+		return nil, err
+	}
+
+	// 顺便清理该用户已过期的会话
+	database.DB.Where("user_id = ? AND expires_at < ?", user.ID, now).Delete(&models.Session{})
+
+	session := models.Session{
+		UserID:     user.ID,
+		TokenHash:  models.HashAPIToken(refreshToken),
+		RotatedAt:  now,
+		UserAgent:  truncate(c.Request.UserAgent(), maxUserAgentLength),
+		IP:         c.ClientIP(),
+		LastUsedAt: now,
+		ExpiresAt:  now.Add(config.RefreshTokenExpire),
+	}
+	if err := database.DB.Create(&session).Error; err != nil {
+		return nil, err
+	}
+
+	return sessionResponse(user, &session, refreshToken)
+}
+
+// sessionResponse 为会话签发新的 access token
+func sessionResponse(user *models.User, session *models.Session, refreshToken string) (*LoginResponse, error) {
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"user_id":  user.ID,
+		"username": user.Username,
+		"sid":      session.ID,
+		"exp":      time.Now().Add(config.AccessTokenExpire).Unix(),
+	})
+	tokenString, err := token.SignedString([]byte(config.GetJWTSecret()))
+	if err != nil {
+		return nil, err
+	}
+
+	return &LoginResponse{
+		Token:        tokenString,
+		RefreshToken: refreshToken,
+		ExpiresIn:    int(config.AccessTokenExpire.Seconds()),
+		Username:     user.Username,
+		Role:         user.Role,
+
+		MustChangePassword: user.MustChangePassword,
+	}, nil
+}
+
+// RefreshToken 用刷新令牌换取新的 access token，同时更换刷新令牌
+func RefreshToken(c *gin.Context) {
+	var req RefreshTokenRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request"})
+		return
+	}
+
+	now := time.Now()
+	hash := models.HashAPIToken(req.RefreshToken)
+
+	var session models.Session
+	if err := database.DB.Where("token_hash = ?", hash).First(&session).Error; err != nil {
+		// 已更换的旧令牌被再次使用
+		if database.DB.Where("previous_hash = ?", hash).First(&session).Error == nil && session.RevokedAt == nil {
+			if now.Sub(session.RotatedAt) > refreshReuseGrace {
+				log.Printf("Refresh token reuse detected, revoking session %d of user %d", session.ID, session.UserID)
+				database.DB.Model(&session).Update("revoked_at", now)
+			}
+		}
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid refresh token"})
+		return
+	}
+	if !session.Active(now) {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Session expired or revoked"})
+		return
+	}
+
+	var user models.User
+	if err := database.DB.First(&user, session.UserID).Error; err != nil || user.Disabled {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found or disabled"})
+		return
+	}
+
+	refreshToken, err := models.GenerateRefreshToken()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate token"})
+		return
+	}
+
+	// 按旧哈希条件更新，并发刷新时只有一个请求成功
+	result := database.DB.Model(&models.Session{}).
+		Where("id = ? AND token_hash = ?", session.ID, hash).
+		Updates(map[string]interface{}{
+			"token_hash":    models.HashAPIToken(refreshToken),
+			"previous_hash": hash,
+			"rotated_at":    now,
+			"last_used_at":  now,
+			"expires_at":    now.Add(config.RefreshTokenExpire),
+			"ip":            c.ClientIP(),
+			"user_agent":    truncate(c.Request.UserAgent(), maxUserAgentLength),
+		})
+	if result.Error != nil || result.RowsAffected == 0 {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid refresh token"})
+		return
+	}
+
+	resp, err := sessionResponse(&user, &session, refreshToken)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate token"})
+		return
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+// Logout 撤销当前会话
+func Logout(c *gin.Context) {
+	if sessionID := currentSessionID(c); sessionID != 0 {
+		database.DB.Model(&models.Session{}).
+			Where("id = ? AND revoked_at IS NULL", sessionID).
+			Update("revoked_at", time.Now())
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Logged out"})
+}
+
+// GetSessions 获取当前用户的有效会话
+func GetSessions(c *gin.Context) {
+	userID := uint(c.MustGet("user_id").(float64))
+	currentID := currentSessionID(c)
+
+	var sessions []models.Session
+	if err := database.DB.
+		Where("user_id = ? AND revoked_at IS NULL AND expires_at > ?", userID, time.Now()).
+		Order("last_used_at DESC").
+		Find(&sessions).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch sessions"})
+		return
+	}
+
+	responses := make([]models.SessionResponse, len(sessions))
+	for i, s := range sessions {
+		responses[i] = models.SessionResponse{Session: s, Current: currentID == s.ID}
+	}
+	c.JSON(http.StatusOK, responses)
+}
+
+// RevokeSession 撤销指定会话（登出某个设备）
+func RevokeSession(c *gin.Context) {
+	userID := uint(c.MustGet("user_id").(float64))
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid session ID"})
+		return
+	}
+
+	result := database.DB.Model(&models.Session{}).
+		Where("id = ? AND user_id = ? AND revoked_at IS NULL", id, userID).
+		Update("revoked_at", time.Now())
+	if result.Error != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to revoke session"})
+		return
+	}
+	if result.RowsAffected == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Session not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Session revoked"})
+}
+
+// RevokeAllSessions 登出所有会话，包括当前会话
+func RevokeAllSessions(c *gin.Context) {
+	userID := uint(c.MustGet("user_id").(float64))
+
+	if err := revokeSessions(database.DB, userID, 0); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to revoke sessions"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "All sessions revoked"})
+}
+
+// revokeSessions 撤销用户的所有会话，exceptID 不为 0 时保留该会话，db 可以是事务
+func revokeSessions(db *gorm.DB, userID, exceptID uint) error {
+	return db.Model(&models.Session{}).
+		Where("user_id = ? AND id != ? AND revoked_at IS NULL", userID, exceptID).
+		Update("revoked_at", time.Now()).Error
+}
+
+// currentSessionID 发起请求的会话 ID，API Token 或代理认证的请求为 0
+func currentSessionID(c *gin.Context) uint {
+	if id, ok := c.Get("session_id"); ok {
+		return id.(uint)
+	}
+	return 0
+}
+
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	return s[:n]
+}
@@ -0,0 +1,116 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"tally/database"
+	"tally/models"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// maxTokensPerUser 每个用户可持有的有效 Token 上限
+const maxTokensPerUser = 50
+
+type CreateAPITokenRequest struct {
+	Name          string `json:"name" binding:"required"`
+	Scope         string `json:"scope" binding:"required"`                 // read / read-write / backup
+	ExpiresInDays int    `json:"expires_in_days" binding:"min=0,max=3650"` // 0 表示永不过期
+}
+
+// CreateAPITokenResponse 创建 Token 的响应，明文只返回这一次
+type CreateAPITokenResponse struct {
+	models.APIToken
+	Token string `json:"token"`
+}
+
+// GetAPITokens 获取当前用户的 API Token（不含明文）
+func GetAPITokens(c *gin.Context) {
+	userID := uint(c.MustGet("user_id").(float64))
+
+	var tokens []models.APIToken
+	if err := database.DB.Where("user_id = ?", userID).Order("id DESC").Find(&tokens).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch tokens"})
+		return
+	}
+
+	c.JSON(http.StatusOK, tokens)
+}
+
+// CreateAPIToken 创建 API Token
+func CreateAPIToken(c *gin.Context) {
+	userID := uint(c.MustGet("user_id").(float64))
+
+	var req CreateAPITokenRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request: " + err.Error()})
+		return
+	}
+	if !models.IsValidScope(req.Scope) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Scope must be 'read', 'read-write' or 'backup'"})
+		return
+	}
+
+	var count int64
+	database.DB.Model(&models.APIToken{}).Where("user_id = ? AND revoked_at IS NULL", userID).Count(&count)
+	if count >= maxTokensPerUser {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Too many tokens, revoke unused ones first"})
+		return
+	}
+
+	plain, err := models.GenerateAPIToken()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate token"})
+		return
+	}
+
+	token := models.APIToken{
+		UserID:    userID,
+		Name:      req.Name,
+		Prefix:    plain[:len(models.APITokenPrefix)+6],
+		TokenHash: models.HashAPIToken(plain),
+		Scope:     req.Scope,
+	}
+	if req.ExpiresInDays > 0 {
+		expiresAt := time.Now().AddDate(0, 0, req.ExpiresInDays)
+		token.ExpiresAt = &expiresAt
+	}
+
+	if err := database.DB.Create(&token).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create token"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, CreateAPITokenResponse{APIToken: token, Token: plain})
+}
+
+// RevokeAPIToken 撤销 API Token，立即失效
+func RevokeAPIToken(c *gin.Context) {
+	userID := uint(c.MustGet("user_id").(float64))
+
+	var token models.APIToken
+	if err := database.DB.Where("user_id = ?", userID).First(&token, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Token not found"})
+		return
+	}
+
+	if token.RevokedAt == nil {
+		now := time.Now()
+		token.RevokedAt = &now
+		if err := database.DB.Save(&token).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to revoke token"})
+			return
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Token revoked"})
+}
+
+// revokeAPITokens 撤销用户所有未撤销的 API Token，db 可以是事务
+func revokeAPITokens(db *gorm.DB, userID uint) error {
+	return db.Model(&models.APIToken{}).
+		Where("user_id = ? AND revoked_at IS NULL", userID).
+		Update("revoked_at", time.Now()).Error
+}
@@ -0,0 +1,275 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"tally/config"
+	"tally/database"
+	"tally/models"
+	"tally/totp"
+
+	"github.com/gin-gonic/gin"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+)
+
+type TwoFactorCodeRequest struct {
+	Code string `json:"code" binding:"required"`
+}
+
+type DisableTwoFactorRequest struct {
+	Password string `json:"password" binding:"required"`
+}
+
+// TwoFactorStatusResponse 两步验证状态
+type TwoFactorStatusResponse struct {
+	Enabled                bool  `json:"enabled"`
+	RecoveryCodesRemaining int64 `json:"recovery_codes_remaining"`
+}
+
+// TwoFactorSetupResponse 待验证的密钥及其 otpauth:// URI
+type TwoFactorSetupResponse struct {
+	Secret string `json:"secret"`
+	URI    string `json:"uri"`
+}
+
+// RecoveryCodesResponse 新生成的恢复码，明文只返回这一次
+type RecoveryCodesResponse struct {
+	RecoveryCodes []string `json:"recovery_codes"`
+}
+
+// verifyTOTP 校验验证码并记录时间步长，同一验证码不能重复使用
+func verifyTOTP(user *models.User, code string) bool {
+	step, ok := totp.Validate(user.TOTPSecret, code, time.Now())
+	if !ok || step <= user.TOTPLastStep {
+		return false
+	}
+
+	// 条件更新，避免并发请求重复使用同一验证码
+	result := database.DB.Model(&models.User{}).
+		Where("id = ? AND totp_last_step < ?", user.ID, step).
+		Update("totp_last_step", step)
+	if result.Error != nil || result.RowsAffected == 0 {
+		return false
+	}
+	user.TOTPLastStep = step
+	return true
+}
+
+// useRecoveryCode 消耗一个未使用的恢复码
+func useRecoveryCode(userID uint, code string) bool {
+	result := database.DB.Model(&models.RecoveryCode{}).
+		Where("user_id = ? AND code_hash = ? AND used_at IS NULL", userID, models.HashRecoveryCode(code)).
+		Update("used_at", time.Now())
+	return result.Error == nil && result.RowsAffected > 0
+}
+
+// regenerateRecoveryCodes 替换用户的全部恢复码
+func regenerateRecoveryCodes(tx *gorm.DB, userID uint) ([]string, error) {
+	if err := tx.Where("user_id = ?", userID).Delete(&models.RecoveryCode{}).Error; err != nil {
+		return nil, err
+	}
+
+	codes := make([]string, models.RecoveryCodeCount)
+	records := make([]models.RecoveryCode, models.RecoveryCodeCount)
+	for i := range codes {
+		code, err := models.GenerateRecoveryCode()
+		if err != nil {
+			return nil, err
+		}
+		codes[i] = code
+		records[i] = models.RecoveryCode{UserID: userID, CodeHash: models.HashRecoveryCode(code)}
+	}
+	if err := tx.Create(&records).Error; err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
+
+// loadCurrentUser 获取当前登录用户
+func loadCurrentUser(c *gin.Context, user *models.User) bool {
+	userID := uint(c.MustGet("user_id").(float64))
+	if err := database.DB.First(user, userID).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return false
+	}
+	return true
+}
+
+// GetTwoFactorStatus 获取两步验证状态
+func GetTwoFactorStatus(c *gin.Context) {
+	var user models.User
+	if !loadCurrentUser(c, &user) {
+		return
+	}
+
+	var remaining int64
+	database.DB.Model(&models.RecoveryCode{}).Where("user_id = ? AND used_at IS NULL", user.ID).Count(&remaining)
+
+	c.JSON(http.StatusOK, TwoFactorStatusResponse{
+		Enabled:                user.TOTPEnabled,
+		RecoveryCodesRemaining: remaining,
+	})
+}
+
+// SetupTwoFactor 生成待验证的 TOTP 密钥，返回用于生成二维码的 URI
+func SetupTwoFactor(c *gin.Context) {
+	var user models.User
+	if !loadCurrentUser(c, &user) {
+		return
+	}
+	if user.TOTPEnabled {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Two-factor authentication is already enabled"})
+		return
+	}
+
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate secret"})
+		return
+	}
+	if err := database.DB.Model(&user).Updates(map[string]interface{}{"totp_secret": secret, "totp_last_step": 0}).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save secret"})
+		return
+	}
+
+	c.JSON(http.StatusOK, TwoFactorSetupResponse{
+		Secret: secret,
+		URI:    totp.ProvisioningURI(config.TOTPIssuer, user.Username, secret),
+	})
+}
+
+// EnableTwoFactor 校验验证码后启用两步验证，并返回恢复码
+func EnableTwoFactor(c *gin.Context) {
+	var req TwoFactorCodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request"})
+		return
+	}
+
+	var user models.User
+	if !loadCurrentUser(c, &user) {
+		return
+	}
+	if user.TOTPEnabled {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Two-factor authentication is already enabled"})
+		return
+	}
+	if user.TOTPSecret == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Call setup first"})
+		return
+	}
+	if !verifyTOTP(&user, req.Code) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid code"})
+		return
+	}
+
+	var codes []string
+	if err := database.DB.Transaction(func(tx *gorm.DB) error {
+		var err error
+		if codes, err = regenerateRecoveryCodes(tx, user.ID); err != nil {
+			return err
+		}
+		return tx.Model(&user).Update("totp_enabled", true).Error
+	}); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to enable two-factor authentication"})
+		return
+	}
+
+	enabled := user
+	enabled.TOTPEnabled = true
+	recordAudit(c, models.AuditUserEnableTOTP, models.AuditTargetUser, user.ID, 0, user, enabled)
+	c.JSON(http.StatusOK, RecoveryCodesResponse{RecoveryCodes: codes})
+}
+
+// DisableTwoFactor 校验密码后关闭两步验证
+func DisableTwoFactor(c *gin.Context) {
+	var req DisableTwoFactorRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request"})
+		return
+	}
+
+	var user models.User
+	if !loadCurrentUser(c, &user) {
+		return
+	}
+	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(req.Password)); err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid password"})
+		return
+	}
+
+	if err := resetTwoFactor(database.DB, user.ID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to disable two-factor authentication"})
+		return
+	}
+
+	disabled := user
+	disabled.TOTPEnabled = false
+	recordAudit(c, models.AuditUserDisableTOTP, models.AuditTargetUser, user.ID, 0, user, disabled)
+	c.JSON(http.StatusOK, gin.H{"message": "Two-factor authentication disabled"})
+}
+
+// RegenerateRecoveryCodes 校验验证码后重新生成恢复码，旧恢复码全部失效
+func RegenerateRecoveryCodes(c *gin.Context) {
+	var req TwoFactorCodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request"})
+		return
+	}
+
+	var user models.User
+	if !loadCurrentUser(c, &user) {
+		return
+	}
+	if !user.TOTPEnabled {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Two-factor authentication is not enabled"})
+		return
+	}
+	if !verifyTOTP(&user, req.Code) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid code"})
+		return
+	}
+
+	codes, err := regenerateRecoveryCodes(database.DB, user.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate recovery codes"})
+		return
+	}
+
+	recordAudit(c, models.AuditUserRecoveryCodes, models.AuditTargetUser, user.ID, 0, user, user)
+	c.JSON(http.StatusOK, RecoveryCodesResponse{RecoveryCodes: codes})
+}
+
+// ResetUserTwoFactor 管理员为丢失验证器的用户关闭两步验证
+func ResetUserTwoFactor(c *gin.Context) {
+	var user models.User
+	if !findUser(c, &user) {
+		return
+	}
+
+	if err := resetTwoFactor(database.DB, user.ID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to reset two-factor authentication"})
+		return
+	}
+
+	reset := user
+	reset.TOTPEnabled = false
+	recordAudit(c, models.AuditUserResetTOTP, models.AuditTargetUser, user.ID, 0, user, reset)
+	c.JSON(http.StatusOK, gin.H{"message": "Two-factor authentication reset"})
+}
+
+// resetTwoFactor 关闭两步验证并清除密钥与恢复码
+func resetTwoFactor(db *gorm.DB, userID uint) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ?", userID).Delete(&models.RecoveryCode{}).Error; err != nil {
+			return err
+		}
+		return tx.Model(&models.User{}).Where("id = ?", userID).Updates(map[string]interface{}{
+			"totp_enabled":   false,
+			"totp_secret":    "",
+			"totp_last_step": 0,
+		}).Error
+	})
+}
@@ -8,6 +8,7 @@ import (
 
 	"github.com/gin-gonic/gin"
 	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
 )
 
 type UpdateUsernameRequest struct {
@@ -20,8 +21,10 @@ type UpdatePasswordRequest struct {
 }
 
 type UserInfoResponse struct {
-	ID       uint   `json:"id"`
-	Username string `json:"username"`
+	ID                 uint   `json:"id"`
+	Username           string `json:"username"`
+	Role               string `json:"role"`
+	MustChangePassword bool   `json:"must_change_password"`
 }
 
 // GetCurrentUser 获取当前用户信息
@@ -35,8 +38,10 @@ func GetCurrentUser(c *gin.Context) {
 	}
 
 	c.JSON(http.StatusOK, UserInfoResponse{
-		ID:       user.ID,
-		Username: user.Username,
+		ID:                 user.ID,
+		Username:           user.Username,
+		Role:               user.Role,
+		MustChangePassword: user.MustChangePassword,
 	})
 }
 
@@ -50,6 +55,12 @@ func UpdateUsername(c *gin.Context) {
 		return
 	}
 
+	var user models.User
+	if err := database.DB.First(&user, userID).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return
+	}
+
 	// 检查新用户名是否已存在
 	var existingUser models.User
 	if err := database.DB.Where("username = ? AND id != ?", req.NewUsername, userID).First(&existingUser).Error; err == nil {
@@ -62,6 +73,12 @@ func UpdateUsername(c *gin.Context) {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update username"})
 		return
 	}
+	// 其他会话的 token 中仍是旧用户名，全部登出，只保留当前会话
+	revokeSessions(database.DB, userID, currentSessionID(c))
+
+	updated := user
+	updated.Username = req.NewUsername
+	recordAudit(c, models.AuditUserUsername, models.AuditTargetUser, userID, 0, user, updated)
 
 	c.JSON(http.StatusOK, gin.H{"message": "Username updated successfully"})
 }
@@ -89,6 +106,11 @@ func UpdatePassword(c *gin.Context) {
 		return
 	}
 
+	if req.NewPassword == req.OldPassword {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "New password must be different from the old password"})
+		return
+	}
+
 	// 生成新密码哈希
 	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(req.NewPassword), bcrypt.DefaultCost)
 	if err != nil {
@@ -96,11 +118,29 @@ func UpdatePassword(c *gin.Context) {
 		return
 	}
 
-	// 更新密码
-	if err := database.DB.Model(&models.User{}).Where("id = ?", userID).Update("password", string(hashedPassword)).Error; err != nil {
+	// 更新密码，同时解除修改密码的要求；登出其他会话并撤销全部 API Token，
+	// 防止被盗用的 token 在修改密码后继续使用
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.User{}).Where("id = ?", userID).Updates(map[string]interface{}{
+			"password":             string(hashedPassword),
+			"must_change_password": false,
+		}).Error; err != nil {
+			return err
+		}
+		if err := revokeSessions(tx, userID, currentSessionID(c)); err != nil {
+			return err
+		}
+		return revokeAPITokens(tx, userID)
+	})
+	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update password"})
 		return
 	}
 
+	// 密码哈希不输出到 JSON，审计事件中只记录操作本身
+	updated := user
+	updated.MustChangePassword = false
+	recordAudit(c, models.AuditUserPassword, models.AuditTargetUser, userID, 0, user, updated)
+
 	c.JSON(http.StatusOK, gin.H{"message": "Password updated successfully"})
 }
@@ -0,0 +1,71 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"tally/database"
+	"tally/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestUpdatePasswordRevokesSessionsAndTokens(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(t.TempDir()); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.Chdir(wd) })
+	t.Setenv("JWT_SECRET", "test-jwt-secret")
+	t.Setenv("ADMIN_PASSWORD", "admin-password")
+	database.InitDB()
+
+	// 管理员 (ID 1) 持有当前会话、另一个会话和两个 API Token
+	now := time.Now()
+	sessions := []models.Session{
+		{UserID: 1, TokenHash: "current", ExpiresAt: now.Add(time.Hour)},
+		{UserID: 1, TokenHash: "other", ExpiresAt: now.Add(time.Hour)},
+	}
+	tokens := []models.APIToken{
+		{UserID: 1, Name: "ci", Prefix: "tly_a", TokenHash: "a", Scope: models.ScopeRead},
+		{UserID: 1, Name: "backup", Prefix: "tly_b", TokenHash: "b", Scope: models.ScopeBackup},
+	}
+	if err := database.DB.Create(&sessions).Error; err != nil {
+		t.Fatal(err)
+	}
+	if err := database.DB.Create(&tokens).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	r := gin.New()
+	r.PUT("/api/user/password", func(c *gin.Context) {
+		c.Set("user_id", float64(1))
+		c.Set("session_id", sessions[0].ID)
+	}, UpdatePassword)
+
+	w := httptest.NewRecorder()
+	body := `{"old_password":"admin-password","new_password":"new-password"}`
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodPut, "/api/user/password", strings.NewReader(body)))
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, body %s", w.Code, w.Body)
+	}
+
+	var active []models.Session
+	database.DB.Where("user_id = ? AND revoked_at IS NULL", 1).Find(&active)
+	if len(active) != 1 || active[0].ID != sessions[0].ID {
+		t.Errorf("active sessions = %v, want only the current one", active)
+	}
+	var activeTokens int64
+	database.DB.Model(&models.APIToken{}).Where("user_id = ? AND revoked_at IS NULL", 1).Count(&activeTokens)
+	if activeTokens != 0 {
+		t.Errorf("active tokens = %d, want 0", activeTokens)
+	}
+}
@@ -0,0 +1,335 @@
+package handlers
+
+import (
+	"net/http"
+
+	"tally/database"
+	"tally/models"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type WorkspaceRequest struct {
+	Name string `json:"name" binding:"required"`
+}
+
+type AddWorkspaceMemberRequest struct {
+	Username string `json:"username" binding:"required"`
+	Role     string `json:"role" binding:"required"`
+}
+
+type UpdateWorkspaceMemberRequest struct {
+	Role string `json:"role" binding:"required"`
+}
+
+// WorkspaceMemberResponse 工作区成员信息
+type WorkspaceMemberResponse struct {
+	UserID    uint   `json:"user_id"`
+	Username  string `json:"username"`
+	Role      string `json:"role"`
+	CreatedAt int64  `json:"created_at"`
+}
+
+// currentWorkspace 获取 WorkspaceMiddleware 解析出的当前工作区
+func currentWorkspace(c *gin.Context) (models.Workspace, bool) {
+	var workspace models.Workspace
+	if err := database.DB.First(&workspace, c.MustGet("workspace_id").(uint)).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Workspace not found"})
+		return workspace, false
+	}
+	return workspace, true
+}
+
+// findWorkspaceMember 按路径参数获取当前工作区的成员
+func findWorkspaceMember(c *gin.Context, member *models.WorkspaceMember) bool {
+	workspaceID := c.MustGet("workspace_id").(uint)
+	if err := database.DB.Where("workspace_id = ? AND user_id = ?", workspaceID, c.Param("user_id")).First(member).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Member not found"})
+		return false
+	}
+	return true
+}
+
+// isLastWorkspaceAdmin 判断成员是否为工作区唯一的管理员
+func isLastWorkspaceAdmin(member *models.WorkspaceMember) bool {
+	if member.Role != models.WorkspaceAdmin {
+		return false
+	}
+	var count int64
+	database.DB.Model(&models.WorkspaceMember{}).
+		Where("workspace_id = ? AND role = ? AND user_id != ?", member.WorkspaceID, models.WorkspaceAdmin, member.UserID).
+		Count(&count)
+	return count == 0
+}
+
+// GetWorkspaces 获取当前用户加入的所有工作区
+func GetWorkspaces(c *gin.Context) {
+	userID := uint(c.MustGet("user_id").(float64))
+
+	var members []models.WorkspaceMember
+	if err := database.DB.Where("user_id = ?", userID).Order("workspace_id").Find(&members).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch workspaces"})
+		return
+	}
+
+	roles := make(map[uint]string, len(members))
+	ids := make([]uint, len(members))
+	for i, m := range members {
+		roles[m.WorkspaceID] = m.Role
+		ids[i] = m.WorkspaceID
+	}
+
+	var workspaces []models.Workspace
+	if err := database.DB.Where("id IN ?", ids).Order("id").Find(&workspaces).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch workspaces"})
+		return
+	}
+
+	responses := make([]models.WorkspaceResponse, len(workspaces))
+	for i := range workspaces {
+		responses[i] = workspaces[i].ToResponse(roles[workspaces[i].ID])
+	}
+
+	c.JSON(http.StatusOK, responses)
+}
+
+// CreateWorkspace 创建共享工作区，创建者成为管理员
+func CreateWorkspace(c *gin.Context) {
+	userID := uint(c.MustGet("user_id").(float64))
+
+	var req WorkspaceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request"})
+		return
+	}
+
+	workspace := models.Workspace{Name: req.Name}
+	if err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&workspace).Error; err != nil {
+			return err
+		}
+		member := models.WorkspaceMember{WorkspaceID: workspace.ID, UserID: userID, Role: models.WorkspaceAdmin}
+		return tx.Create(&member).Error
+	}); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create workspace"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, workspace.ToResponse(models.WorkspaceAdmin))
+}
+
+// GetWorkspace 获取当前工作区
+func GetWorkspace(c *gin.Context) {
+	workspace, ok := currentWorkspace(c)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, workspace.ToResponse(c.GetString("workspace_role")))
+}
+
+// UpdateWorkspace 重命名工作区
+func UpdateWorkspace(c *gin.Context) {
+	var req WorkspaceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request"})
+		return
+	}
+
+	workspace, ok := currentWorkspace(c)
+	if !ok {
+		return
+	}
+
+	workspace.Name = req.Name
+	if err := database.DB.Save(&workspace).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update workspace"})
+		return
+	}
+
+	c.JSON(http.StatusOK, workspace.ToResponse(c.GetString("workspace_role")))
+}
+
+// DeleteWorkspace 删除共享工作区及其所有资源，个人工作区不可删除
+func DeleteWorkspace(c *gin.Context) {
+	workspace, ok := currentWorkspace(c)
+	if !ok {
+		return
+	}
+	if workspace.Personal() {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Cannot delete a personal workspace"})
+		return
+	}
+
+	if err := database.DB.Transaction(func(tx *gorm.DB) error {
+		return deleteWorkspaceData(tx, workspace.ID)
+	}); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete workspace"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Workspace deleted"})
+}
+
+// GetWorkspaceMembers 获取工作区成员
+func GetWorkspaceMembers(c *gin.Context) {
+	workspaceID := c.MustGet("workspace_id").(uint)
+
+	var members []models.WorkspaceMember
+	if err := database.DB.Where("workspace_id = ?", workspaceID).Order("id").Find(&members).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch members"})
+		return
+	}
+
+	ids := make([]uint, len(members))
+	for i, m := range members {
+		ids[i] = m.UserID
+	}
+	var users []models.User
+	database.DB.Where("id IN ?", ids).Find(&users)
+	usernames := make(map[uint]string, len(users))
+	for _, u := range users {
+		usernames[u.ID] = u.Username
+	}
+
+	responses := make([]WorkspaceMemberResponse, len(members))
+	for i, m := range members {
+		responses[i] = WorkspaceMemberResponse{
+			UserID:    m.UserID,
+			Username:  usernames[m.UserID],
+			Role:      m.Role,
+			CreatedAt: m.CreatedAt.Unix(),
+		}
+	}
+
+	c.JSON(http.StatusOK, responses)
+}
+
+// AddWorkspaceMember 按用户名添加成员，个人工作区不可共享
+func AddWorkspaceMember(c *gin.Context) {
+	var req AddWorkspaceMemberRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request"})
+		return
+	}
+	if !models.IsValidWorkspaceRole(req.Role) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Role must be 'viewer', 'editor' or 'admin'"})
+		return
+	}
+
+	workspace, ok := currentWorkspace(c)
+	if !ok {
+		return
+	}
+	if workspace.Personal() {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Cannot share a personal workspace"})
+		return
+	}
+
+	var user models.User
+	if err := database.DB.Where("username = ?", req.Username).First(&user).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return
+	}
+
+	var count int64
+	database.DB.Model(&models.WorkspaceMember{}).Where("workspace_id = ? AND user_id = ?", workspace.ID, user.ID).Count(&count)
+	if count > 0 {
+		c.JSON(http.StatusConflict, gin.H{"error": "User is already a member"})
+		return
+	}
+
+	member := models.WorkspaceMember{WorkspaceID: workspace.ID, UserID: user.ID, Role: req.Role}
+	if err := database.DB.Create(&member).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to add member"})
+		return
+	}
+
+	recordAudit(c, models.AuditMemberAdd, models.AuditTargetUser, user.ID, workspace.ID, nil, member)
+	c.JSON(http.StatusCreated, WorkspaceMemberResponse{
+		UserID:    user.ID,
+		Username:  user.Username,
+		Role:      member.Role,
+		CreatedAt: member.CreatedAt.Unix(),
+	})
+}
+
+// UpdateWorkspaceMember 修改成员角色，工作区至少保留一个管理员
+func UpdateWorkspaceMember(c *gin.Context) {
+	var req UpdateWorkspaceMemberRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request"})
+		return
+	}
+	if !models.IsValidWorkspaceRole(req.Role) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Role must be 'viewer', 'editor' or 'admin'"})
+		return
+	}
+
+	var member models.WorkspaceMember
+	if !findWorkspaceMember(c, &member) {
+		return
+	}
+	if req.Role != models.WorkspaceAdmin && isLastWorkspaceAdmin(&member) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Cannot demote the last workspace admin"})
+		return
+	}
+
+	before := member
+	member.Role = req.Role
+	if err := database.DB.Save(&member).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update member"})
+		return
+	}
+
+	recordAudit(c, models.AuditMemberUpdate, models.AuditTargetUser, member.UserID, member.WorkspaceID, before, member)
+	c.JSON(http.StatusOK, gin.H{"message": "Member updated"})
+}
+
+// RemoveWorkspaceMember 移除成员，管理员可移除任何成员，其他成员只能退出
+func RemoveWorkspaceMember(c *gin.Context) {
+	userID := uint(c.MustGet("user_id").(float64))
+
+	var member models.WorkspaceMember
+	if !findWorkspaceMember(c, &member) {
+		return
+	}
+	if member.UserID != userID && c.GetString("workspace_role") != models.WorkspaceAdmin {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Permission denied"})
+		return
+	}
+	if isLastWorkspaceAdmin(&member) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Cannot remove the last workspace admin"})
+		return
+	}
+
+	if err := database.DB.Delete(&member).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to remove member"})
+		return
+	}
+
+	recordAudit(c, models.AuditMemberRemove, models.AuditTargetUser, member.UserID, member.WorkspaceID, member, nil)
+	c.JSON(http.StatusOK, gin.H{"message": "Member removed"})
+}
+
+// deleteWorkspaceData 删除工作区及其资源、分组设置和成员
+func deleteWorkspaceData(tx *gorm.DB, workspaceID uint) error {
+	resources := tx.Model(&models.Resource{}).Select("id").Where("workspace_id = ?", workspaceID)
+	if err := deleteResourceRecords(tx, resources); err != nil {
+		return err
+	}
+
+	for _, model := range []interface{}{
+		&models.Resource{},
+		&models.Renewal{},
+		&models.GroupSetting{},
+		&models.WorkspaceMember{},
+	} {
+		if err := tx.Where("workspace_id = ?", workspaceID).Delete(model).Error; err != nil {
+			return err
+		}
+	}
+
+	return tx.Delete(&models.Workspace{}, workspaceID).Error
+}
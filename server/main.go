@@ -12,6 +12,7 @@ import (
 	"tally/config"
 	"tally/database"
 	"tally/routes"
+	"tally/scheduler"
 
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
@@ -37,6 +38,12 @@ func main() {
 	// 初始化数据库
 	database.InitDB()
 
+	// 加载 JWT 密钥，首次启动时生成
+	config.GetJWTSecret()
+
+	// 启动到期提醒调度
+	scheduler.Start()
+
 	// 设置 Gin 模式
 	if os.Getenv("GIN_MODE") == "release" {
 		gin.SetMode(gin.ReleaseMode)
@@ -44,11 +51,16 @@ func main() {
 
 	r := gin.Default()
 
+	// 只信任可信代理传递的 X-Forwarded-For，登录限流、会话和审计日志都依赖客户端地址
+	if err := r.SetTrustedProxies(config.GetTrustedProxies()); err != nil {
+		log.Fatal("Invalid trusted proxies:", err)
+	}
+
 	// CORS 配置（开发模式）
 	r.Use(cors.New(cors.Config{
 		AllowOrigins:     []string{"http://localhost:5173", "http://localhost:3000"},
 		AllowMethods:     []string{"GET", "POST", "PATCH", "PUT", "DELETE", "OPTIONS"},
-		AllowHeaders:     []string{"Origin", "Content-Type", "Authorization"},
+		AllowHeaders:     []string{"Origin", "Content-Type", "Authorization", "X-Workspace-ID"},
 		AllowCredentials: true,
 	}))
 
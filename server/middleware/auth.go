@@ -3,50 +3,196 @@ package middleware
 import (
 	"net/http"
 	"strings"
+	"time"
 
 	"tally/config"
+	"tally/database"
+	"tally/models"
 
 	"github.com/gin-gonic/gin"
 	"github.com/golang-jwt/jwt/v5"
 )
 
+// lastUsedInterval API Token 最近使用时间的最小更新间隔，避免每个请求都写库
+const lastUsedInterval = time.Minute
+
+// passwordChangeRoutes 必须修改密码的用户仍可访问的接口
+var passwordChangeRoutes = map[string]string{
+	"/api/user":          http.MethodGet,
+	"/api/user/password": http.MethodPut,
+	"/api/auth/logout":   http.MethodPost,
+}
+
+// tokenForbiddenPaths API Token 不可访问的账户管理接口，这些操作需要交互式登录
+var tokenForbiddenPaths = []string{"/api/tokens", "/api/user/", "/api/admin/"}
+
 func AuthMiddleware() gin.HandlerFunc {
+	proxyAuth := config.GetProxyAuthConfig()
+
 	return func(c *gin.Context) {
 		authHeader := c.GetHeader("Authorization")
-		if authHeader == "" {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Authorization header required"})
-			c.Abort()
-			return
+		var userID, sessionID uint
+		var scope string
+
+		if username, ok := proxyUsername(c, proxyAuth); ok && authHeader == "" {
+			// 未携带 token 时，信任可信反向代理传递的用户名
+			id, ok := proxyUser(username, proxyAuth.AutoCreate)
+			if !ok {
+				c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found or disabled"})
+				c.Abort()
+				return
+			}
+			userID = id
+		} else {
+			if authHeader == "" {
+				c.JSON(http.StatusUnauthorized, gin.H{"error": "Authorization header required"})
+				c.Abort()
+				return
+			}
+
+			// 解析 Bearer token
+			parts := strings.Split(authHeader, " ")
+			if len(parts) != 2 || parts[0] != "Bearer" {
+				c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid authorization header format"})
+				c.Abort()
+				return
+			}
+
+			tokenString := parts[1]
+			if models.IsAPIToken(tokenString) {
+				apiToken, ok := authenticateAPIToken(tokenString)
+				if !ok {
+					c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid token"})
+					c.Abort()
+					return
+				}
+				if !scopeAllows(apiToken.Scope, c.Request.Method, c.FullPath()) {
+					c.JSON(http.StatusForbidden, gin.H{"error": "Token scope does not allow this operation"})
+					c.Abort()
+					return
+				}
+				userID = apiToken.UserID
+				scope = apiToken.Scope
+			} else {
+				id, sid, ok := parseJWT(tokenString)
+				if !ok || !sessionActive(sid, id) {
+					c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid token"})
+					c.Abort()
+					return
+				}
+				userID = id
+				sessionID = sid
+			}
 		}
 
-		// 解析 Bearer token
-		parts := strings.Split(authHeader, " ")
-		if len(parts) != 2 || parts[0] != "Bearer" {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid authorization header format"})
+		// 每次请求重新加载用户，使禁用、删除和角色变更立即生效
+		var user models.User
+		if err := database.DB.First(&user, userID).Error; err != nil || user.Disabled {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found or disabled"})
 			c.Abort()
 			return
 		}
 
-		tokenString := parts[1]
-		token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
-			if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-				return nil, jwt.ErrSignatureInvalid
-			}
-			return []byte(config.GetJWTSecret()), nil
-		})
-
-		if err != nil || !token.Valid {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid token"})
+		// 必须先修改密码，只允许查看账户、修改密码和登出
+		if user.MustChangePassword && !passwordChangeAllowed(c.Request.Method, c.FullPath()) {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Password change required", "must_change_password": true})
 			c.Abort()
 			return
 		}
 
-		// 从 token 中提取用户信息
-		if claims, ok := token.Claims.(jwt.MapClaims); ok {
-			c.Set("user_id", claims["user_id"])
-			c.Set("username", claims["username"])
+		c.Set("user_id", float64(user.ID))
+		c.Set("username", user.Username)
+		c.Set("role", user.Role)
+		if scope != "" {
+			c.Set("token_scope", scope)
+		}
+		if sessionID != 0 {
+			c.Set("session_id", sessionID)
 		}
 
 		c.Next()
 	}
 }
+
+// parseJWT 校验 JWT 并返回其中的用户 ID 和会话 ID
+func parseJWT(tokenString string) (uint, uint, bool) {
+	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, jwt.ErrSignatureInvalid
+		}
+		return []byte(config.GetJWTSecret()), nil
+	})
+	if err != nil || !token.Valid {
+		return 0, 0, false
+	}
+
+	// 从 token 中提取用户信息
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok {
+		return 0, 0, false
+	}
+	// 两步验证挑战、OIDC 登录状态等用途的 token 只能用于完成登录
+	if _, isChallenge := claims["purpose"]; isChallenge {
+		return 0, 0, false
+	}
+	userID, ok := claims["user_id"].(float64)
+	if !ok {
+		return 0, 0, false
+	}
+	// 未关联会话的旧版 token 不再接受，需要重新登录
+	sessionID, ok := claims["sid"].(float64)
+	if !ok {
+		return 0, 0, false
+	}
+	return uint(userID), uint(sessionID), true
+}
+
+// sessionActive 判断 access token 关联的会话是否仍然有效，撤销会话后 token 立即失效
+func sessionActive(sessionID, userID uint) bool {
+	var session models.Session
+	if err := database.DB.First(&session, sessionID).Error; err != nil {
+		return false
+	}
+	return session.UserID == userID && session.Active(time.Now())
+}
+
+// authenticateAPIToken 按哈希查找未撤销、未过期的 API Token，并记录最近使用时间
+func authenticateAPIToken(tokenString string) (*models.APIToken, bool) {
+	var apiToken models.APIToken
+	if err := database.DB.Where("token_hash = ?", models.HashAPIToken(tokenString)).First(&apiToken).Error; err != nil {
+		return nil, false
+	}
+
+	now := time.Now()
+	if !apiToken.Active(now) {
+		return nil, false
+	}
+	if apiToken.LastUsedAt == nil || now.Sub(*apiToken.LastUsedAt) >= lastUsedInterval {
+		database.DB.Model(&apiToken).UpdateColumn("last_used_at", now)
+	}
+	return &apiToken, true
+}
+
+// scopeAllows 判断 API Token 的权限范围是否允许访问该路由
+func scopeAllows(scope, method, path string) bool {
+	for _, p := range tokenForbiddenPaths {
+		if strings.HasPrefix(path, p) {
+			return false
+		}
+	}
+
+	switch scope {
+	case models.ScopeReadWrite:
+		return true
+	case models.ScopeRead:
+		return method == http.MethodGet || method == http.MethodHead
+	case models.ScopeBackup:
+		return strings.HasSuffix(path, "/backup") || strings.HasSuffix(path, "/backup/restore")
+	}
+	return false
+}
+
+// passwordChangeAllowed 判断必须修改密码的用户能否访问该路由
+func passwordChangeAllowed(method, path string) bool {
+	return passwordChangeRoutes[path] == method
+}
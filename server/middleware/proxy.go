@@ -0,0 +1,73 @@
+package middleware
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"log"
+	"net"
+	"strings"
+
+	"tally/config"
+	"tally/database"
+	"tally/models"
+
+	"github.com/gin-gonic/gin"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+)
+
+// proxyUsername 请求来自可信代理且携带用户名请求头时返回该用户名
+//
+// 只使用 TCP 连接的来源地址判断，不读取 X-Forwarded-For，避免客户端伪造
+func proxyUsername(c *gin.Context, cfg config.ProxyAuthConfig) (string, bool) {
+	if !cfg.Enabled() {
+		return "", false
+	}
+	username := strings.TrimSpace(c.GetHeader(cfg.Header))
+	if username == "" {
+		return "", false
+	}
+	host, _, err := net.SplitHostPort(c.Request.RemoteAddr)
+	if err != nil {
+		host = c.Request.RemoteAddr
+	}
+	ip := net.ParseIP(host)
+	if ip == nil || !cfg.Trusted(ip) {
+		return "", false
+	}
+	return username, true
+}
+
+// proxyUser 按用户名查找代理认证的用户，允许时自动创建
+func proxyUser(username string, autoCreate bool) (uint, bool) {
+	var user models.User
+	err := database.DB.Where("username = ?", username).First(&user).Error
+	if err == nil {
+		return user.ID, true
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) || !autoCreate {
+		return 0, false
+	}
+
+	// 代理认证的用户不使用密码，随机密码仅用于满足非空约束
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return 0, false
+	}
+	hashed, err := bcrypt.GenerateFromPassword([]byte(hex.EncodeToString(b)), bcrypt.DefaultCost)
+	if err != nil {
+		return 0, false
+	}
+
+	user = models.User{Username: username, Password: string(hashed), Role: models.RoleUser}
+	if err := database.DB.Create(&user).Error; err != nil {
+		// 并发请求可能已经创建了同名用户
+		if database.DB.Where("username = ?", username).First(&user).Error == nil {
+			return user.ID, true
+		}
+		return 0, false
+	}
+	log.Printf("Created user %q from reverse proxy header", username)
+	return user.ID, true
+}
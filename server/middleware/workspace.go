@@ -0,0 +1,60 @@
+package middleware
+
+import (
+	"net/http"
+
+	"tally/database"
+	"tally/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// WorkspaceHeader 选择当前工作区的请求头
+const WorkspaceHeader = "X-Workspace-ID"
+
+// WorkspaceMiddleware 解析当前工作区并校验成员身份，需在 AuthMiddleware 之后使用
+// 工作区依次取自路径参数 :workspace_id、X-Workspace-ID 请求头，均未提供时使用个人工作区
+func WorkspaceMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID := uint(c.MustGet("user_id").(float64))
+
+		id := c.Param("workspace_id")
+		if id == "" {
+			id = c.GetHeader(WorkspaceHeader)
+		}
+
+		query := database.DB.Where("user_id = ?", userID)
+		if id != "" {
+			query = query.Where("workspace_id = ?", id)
+		} else {
+			query = query.Where("workspace_id = (?)",
+				database.DB.Model(&models.Workspace{}).Select("id").Where("owner_id = ?", userID))
+		}
+
+		// 非成员与不存在的工作区同样返回 404，避免泄露工作区是否存在
+		var member models.WorkspaceMember
+		if err := query.First(&member).Error; err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Workspace not found"})
+			c.Abort()
+			return
+		}
+
+		c.Set("workspace_id", member.WorkspaceID)
+		c.Set("workspace_role", member.Role)
+
+		c.Next()
+	}
+}
+
+// RequireWorkspaceRole 要求当前用户在工作区中的角色不低于 role，需在 WorkspaceMiddleware 之后使用
+func RequireWorkspaceRole(role string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !models.WorkspaceRoleAtLeast(c.GetString("workspace_role"), role) {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Permission denied"})
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
@@ -0,0 +1,143 @@
+package models
+
+import (
+	"bytes"
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// 审计事件的操作
+const (
+	AuditResourceCreate = "resource.create"
+	AuditResourceUpdate = "resource.update"
+	AuditResourceRenew  = "resource.renew"
+	AuditResourceDelete = "resource.delete"
+	AuditResourceUndo   = "resource.undo_renewal"
+	AuditResourceAuto   = "resource.auto_renew" // 后台任务自动续费，ActorID 为 0
+	AuditResourceImport = "resource.import"     // 还原备份，TargetID 为 0
+	AuditUserUsername   = "user.update_username"
+	AuditUserPassword   = "user.update_password"
+)
+
+// 账户变更的审计操作，TargetID 为被操作的用户，成员变更的 WorkspaceID 为所在工作区
+const (
+	AuditUserCreate        = "user.create"
+	AuditUserUpdate        = "user.update" // 管理员修改角色或禁用状态
+	AuditUserResetPassword = "user.reset_password"
+	AuditUserUnlock        = "user.unlock"
+	AuditUserDelete        = "user.delete"
+	AuditUserEnableTOTP    = "user.enable_2fa"
+	AuditUserDisableTOTP   = "user.disable_2fa"
+	AuditUserResetTOTP     = "user.reset_2fa" // 管理员关闭其他用户的两步验证
+	AuditUserRecoveryCodes = "user.regenerate_recovery_codes"
+	AuditMemberAdd         = "workspace.add_member"
+	AuditMemberUpdate      = "workspace.update_member"
+	AuditMemberRemove      = "workspace.remove_member"
+)
+
+// 审计事件的对象类型
+const (
+	AuditTargetResource = "resource"
+	AuditTargetUser     = "user"
+)
+
+// AuditEvent 审计事件，只追加不修改，超过保留期限后由调度器清理
+type AuditEvent struct {
+	ID          uint         `gorm:"primaryKey" json:"id"`
+	ActorID     uint         `gorm:"index;not null" json:"actor_id"`
+	ActorName   string       `json:"actor_name"` // 操作时的用户名，用户改名或删除后仍可辨认
+	Action      string       `gorm:"index;not null" json:"action"`
+	TargetType  string       `gorm:"index:idx_audit_target;not null" json:"target_type"`
+	TargetID    uint         `gorm:"index:idx_audit_target;not null" json:"target_id"`
+	WorkspaceID uint         `gorm:"index;not null;default:0" json:"workspace_id"` // 账户操作为 0，成员变更为所在工作区
+	Changes     AuditChanges `gorm:"type:text" json:"changes"`
+	IP          string       `json:"ip"`
+	UserAgent   string       `json:"user_agent"`
+	CreatedAt   time.Time    `gorm:"index" json:"created_at"`
+}
+
+// AuditChange 单个字段变更前后的 JSON 值，创建时没有 before，删除时没有 after
+type AuditChange struct {
+	Before json.RawMessage `json:"before,omitempty"`
+	After  json.RawMessage `json:"after,omitempty"`
+}
+
+// AuditChanges 按字段名记录的变更，以 JSON 文本存储
+type AuditChanges map[string]AuditChange
+
+// Value 实现 driver.Valuer
+func (c AuditChanges) Value() (driver.Value, error) {
+	if c == nil {
+		return nil, nil
+	}
+	b, err := json.Marshal(map[string]AuditChange(c))
+	if err != nil {
+		return nil, err
+	}
+	return string(b), nil
+}
+
+// Scan 实现 sql.Scanner
+func (c *AuditChanges) Scan(value interface{}) error {
+	var data []byte
+	switch v := value.(type) {
+	case nil:
+		*c = nil
+		return nil
+	case string:
+		data = []byte(v)
+	case []byte:
+		data = v
+	default:
+		return fmt.Errorf("unsupported AuditChanges value: %T", value)
+	}
+
+	var changes map[string]AuditChange
+	if err := json.Unmarshal(data, &changes); err != nil {
+		return err
+	}
+	*c = changes
+	return nil
+}
+
+// DiffAudit 比较 before 和 after 的 JSON 表示，返回值不同的顶层字段，nil 表示对象不存在
+func DiffAudit(before, after interface{}) (AuditChanges, error) {
+	beforeFields, err := auditFields(before)
+	if err != nil {
+		return nil, err
+	}
+	afterFields, err := auditFields(after)
+	if err != nil {
+		return nil, err
+	}
+
+	changes := AuditChanges{}
+	for name, b := range beforeFields {
+		if a, ok := afterFields[name]; !ok || !bytes.Equal(a, b) {
+			changes[name] = AuditChange{Before: b, After: a}
+		}
+	}
+	for name, a := range afterFields {
+		if _, ok := beforeFields[name]; !ok {
+			changes[name] = AuditChange{After: a}
+		}
+	}
+	return changes, nil
+}
+
+func auditFields(v interface{}) (map[string]json.RawMessage, error) {
+	if v == nil {
+		return nil, nil
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(b, &fields); err != nil {
+		return nil, err
+	}
+	return fields, nil
+}
@@ -0,0 +1,112 @@
+package models
+
+import (
+	"math"
+	"strings"
+	"time"
+)
+
+// 计费周期
+const (
+	BillingMonthly   = "monthly"
+	BillingQuarterly = "quarterly"
+	BillingYearly    = "yearly"
+	BillingOneOff    = "one_off" // 一次性付费，不再续费
+	BillingCustom    = "custom"  // 按 BillingDays 天续费
+)
+
+// MaxBillingDays 自定义计费周期的最大天数
+const MaxBillingDays = 3650
+
+// ValidBillingCycle 判断计费周期是否合法，空字符串表示未设置
+func ValidBillingCycle(cycle string) bool {
+	switch cycle {
+	case "", BillingMonthly, BillingQuarterly, BillingYearly, BillingOneOff, BillingCustom:
+		return true
+	}
+	return false
+}
+
+// Recurring 判断计费周期是否会周期性续费
+func Recurring(cycle string) bool {
+	return cycle != "" && cycle != BillingOneOff
+}
+
+// billingMonths 按月计算的计费周期包含的月数，其他周期为 0
+func billingMonths(cycle string) int {
+	switch cycle {
+	case BillingMonthly:
+		return 1
+	case BillingQuarterly:
+		return 3
+	case BillingYearly:
+		return 12
+	}
+	return 0
+}
+
+// AddMonths 按日历加减月份，目标月份没有对应日期时取该月最后一天（1 月 31 日加 1 个月为 2 月 28 / 29 日）
+func AddMonths(t time.Time, months int) time.Time {
+	year, month, day := t.Date()
+	first := time.Date(year, month+time.Month(months), 1, t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), t.Location())
+	if last := first.AddDate(0, 1, -1).Day(); day > last {
+		day = last
+	}
+	return first.AddDate(0, 0, day-1)
+}
+
+// NextBillingDate 返回从 start 起第 n 个计费周期的日期，始终从 start 计算，月末日期不会逐期漂移
+//
+// 非周期性计费返回 false
+func NextBillingDate(start time.Time, cycle string, billingDays, n int) (time.Time, bool) {
+	if months := billingMonths(cycle); months > 0 {
+		return AddMonths(start, months*n), true
+	}
+	if cycle == BillingCustom && billingDays > 0 {
+		return start.AddDate(0, 0, billingDays*n), true
+	}
+	return time.Time{}, false
+}
+
+// billingCycleDays 计费周期的平均天数，非周期性计费为 0
+func billingCycleDays(cycle string, billingDays int) float64 {
+	if months := billingMonths(cycle); months > 0 {
+		return float64(months) * 365.25 / 12
+	}
+	if cycle == BillingCustom {
+		return float64(billingDays)
+	}
+	return 0
+}
+
+// RoundMoney 金额保留两位小数
+func RoundMoney(v float64) float64 {
+	return math.Round(v*100) / 100
+}
+
+// NormalizeCurrency 货币代码统一为大写
+func NormalizeCurrency(code string) string {
+	return strings.ToUpper(strings.TrimSpace(code))
+}
+
+// ValidCurrency 判断是否为 ISO 4217 货币代码（不含贵金属、基金等非货币代码）
+func ValidCurrency(code string) bool {
+	return currencies[code]
+}
+
+var currencies = func() map[string]bool {
+	codes := strings.Fields(`
+		AED AFN ALL AMD ANG AOA ARS AUD AWG AZN BAM BBD BDT BGN BHD BIF BMD BND BOB BRL
+		BSD BTN BWP BYN BZD CAD CDF CHF CLP CNY COP CRC CUP CVE CZK DJF DKK DOP DZD EGP
+		ERN ETB EUR FJD FKP GBP GEL GHS GIP GMD GNF GTQ GYD HKD HNL HTG HUF IDR ILS INR
+		IQD IRR ISK JMD JOD JPY KES KGS KHR KMF KPW KRW KWD KYD KZT LAK LBP LKR LRD LSL
+		LYD MAD MDL MGA MKD MMK MNT MOP MRU MUR MVR MWK MXN MYR MZN NAD NGN NIO NOK NPR
+		NZD OMR PAB PEN PGK PHP PKR PLN PYG QAR RON RSD RUB RWF SAR SBD SCR SDG SEK SGD
+		SHP SLE SOS SRD SSP STN SVC SYP SZL THB TJS TMT TND TOP TRY TTD TWD TZS UAH UGX
+		USD UYU UZS VES VND VUV WST XAF XCD XCG XOF XPF YER ZAR ZMW ZWG`)
+	set := make(map[string]bool, len(codes))
+	for _, code := range codes {
+		set[code] = true
+	}
+	return set
+}()
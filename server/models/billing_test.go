@@ -0,0 +1,76 @@
+package models
+
+import (
+	"testing"
+	"time"
+)
+
+func date(year int, month time.Month, day int) time.Time {
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+func TestAddMonths(t *testing.T) {
+	tests := []struct {
+		from   time.Time
+		months int
+		want   time.Time
+	}{
+		{date(2025, 1, 15), 1, date(2025, 2, 15)},
+		{date(2025, 1, 31), 1, date(2025, 2, 28)},
+		{date(2024, 1, 31), 1, date(2024, 2, 29)},
+		{date(2025, 3, 31), 1, date(2025, 4, 30)},
+		{date(2025, 8, 31), 3, date(2025, 11, 30)},
+		{date(2024, 2, 29), 12, date(2025, 2, 28)},
+		{date(2024, 2, 29), 48, date(2028, 2, 29)},
+		{date(2025, 11, 30), 3, date(2026, 2, 28)},
+		{date(2025, 3, 31), -1, date(2025, 2, 28)},
+		{date(2025, 1, 31), -2, date(2024, 11, 30)},
+		{date(2025, 5, 31), 0, date(2025, 5, 31)},
+	}
+	for _, tt := range tests {
+		if got := AddMonths(tt.from, tt.months); !got.Equal(tt.want) {
+			t.Errorf("AddMonths(%s, %d) = %s, want %s", tt.from.Format("2006-01-02"), tt.months, got.Format("2006-01-02"), tt.want.Format("2006-01-02"))
+		}
+	}
+}
+
+func TestAddMonthsKeepsWallClock(t *testing.T) {
+	loc, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Skip("time zone data unavailable")
+	}
+	// 跨越夏令时切换后仍保持当地时间
+	from := time.Date(2025, 1, 31, 9, 30, 0, 0, loc)
+	got := AddMonths(from, 3)
+	if want := time.Date(2025, 4, 30, 9, 30, 0, 0, loc); !got.Equal(want) {
+		t.Errorf("AddMonths = %s, want %s", got, want)
+	}
+}
+
+func TestNextBillingDate(t *testing.T) {
+	start := date(2025, 1, 31)
+	tests := []struct {
+		cycle string
+		days  int
+		n     int
+		want  time.Time
+		ok    bool
+	}{
+		{BillingMonthly, 0, 1, date(2025, 2, 28), true},
+		// 始终从 start 计算，第二期回到 31 日而不是沿用 2 月 28 日
+		{BillingMonthly, 0, 2, date(2025, 3, 31), true},
+		{BillingQuarterly, 0, 1, date(2025, 4, 30), true},
+		{BillingQuarterly, 0, 2, date(2025, 7, 31), true},
+		{BillingYearly, 0, 1, date(2026, 1, 31), true},
+		{BillingCustom, 10, 3, date(2025, 3, 2), true},
+		{BillingCustom, 0, 1, time.Time{}, false},
+		{BillingOneOff, 0, 1, time.Time{}, false},
+		{"", 0, 1, time.Time{}, false},
+	}
+	for _, tt := range tests {
+		got, ok := NextBillingDate(start, tt.cycle, tt.days, tt.n)
+		if ok != tt.ok || !got.Equal(tt.want) {
+			t.Errorf("NextBillingDate(%q, %d, %d) = %s, %v, want %s, %v", tt.cycle, tt.days, tt.n, got.Format("2006-01-02"), ok, tt.want.Format("2006-01-02"), tt.ok)
+		}
+	}
+}
@@ -0,0 +1,61 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// DigestSetting 用户的到期摘要设置
+type DigestSetting struct {
+	ID         uint    `gorm:"primaryKey" json:"-"`
+	UserID     uint    `gorm:"uniqueIndex;not null" json:"-"`
+	Enabled    bool    `gorm:"not null" json:"enabled"`
+	Frequency  string  `gorm:"not null" json:"frequency"` // daily / weekly
+	Hour       int     `gorm:"not null" json:"hour"`      // 发送时刻（0~23 点）
+	Weekday    int     `gorm:"not null" json:"weekday"`   // 每周摘要的发送日，0 为周日
+	Timezone   string  `gorm:"not null" json:"timezone"`  // IANA 时区，如 Asia/Shanghai
+	ChannelIDs IntList `gorm:"type:text" json:"channel_ids"`
+	// LastPeriod 最近一次已处理的周期标识，避免同一周期重复发送
+	LastPeriod string `json:"-"`
+}
+
+// DigestHistory 已发送的摘要记录
+type DigestHistory struct {
+	ID         uint      `gorm:"primaryKey" json:"id"`
+	UserID     uint      `gorm:"index;not null" json:"user_id"`
+	Frequency  string    `gorm:"not null" json:"frequency"`
+	Period     string    `gorm:"not null" json:"period"`
+	ItemCount  int       `gorm:"not null" json:"item_count"`
+	ChannelIDs IntList   `gorm:"type:text" json:"channel_ids"` // 发送成功的渠道
+	Content    string    `gorm:"type:text" json:"-"`           // 摘要内容 JSON
+	SentAt     time.Time `gorm:"not null" json:"sent_at"`
+}
+
+// DigestHistoryResponse 摘要记录响应格式
+type DigestHistoryResponse struct {
+	ID         uint            `json:"id"`
+	Frequency  string          `json:"frequency"`
+	Period     string          `json:"period"`
+	ItemCount  int             `json:"item_count"`
+	ChannelIDs IntList         `json:"channel_ids"`
+	Content    json.RawMessage `json:"content"`
+	SentAt     int64           `json:"sent_at"`
+}
+
+// ToResponse 转换为响应格式
+func (h *DigestHistory) ToResponse() DigestHistoryResponse {
+	content := json.RawMessage(h.Content)
+	if len(content) == 0 {
+		content = json.RawMessage("null")
+	}
+
+	return DigestHistoryResponse{
+		ID:         h.ID,
+		Frequency:  h.Frequency,
+		Period:     h.Period,
+		ItemCount:  h.ItemCount,
+		ChannelIDs: h.ChannelIDs,
+		Content:    content,
+		SentAt:     h.SentAt.Unix(),
+	}
+}
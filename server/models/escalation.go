@@ -0,0 +1,107 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// 升级状态
+const (
+	EscalationActive       = "active"       // 等待确认，到时间后通知下一级
+	EscalationAcknowledged = "acknowledged" // 已确认，停止升级
+	EscalationExhausted    = "exhausted"    // 所有级别均已通知
+	EscalationResolved     = "resolved"     // 资源已续约或策略被移除
+)
+
+// EscalationStep 升级链中的一级，ChannelID 与 UserID 二选一
+type EscalationStep struct {
+	AfterHours int  `json:"after_hours"`          // 上一级通知后（第一级为升级开始后）仍未确认多少小时后通知本级
+	ChannelID  uint `json:"channel_id,omitempty"` // 通知策略所有者的指定渠道
+	UserID     uint `json:"user_id,omitempty"`    // 通知指定用户所有启用的渠道
+}
+
+// EscalationSteps 以 JSON 文本存储的升级链
+type EscalationSteps []EscalationStep
+
+// Value 实现 driver.Valuer
+func (s EscalationSteps) Value() (driver.Value, error) {
+	b, err := json.Marshal([]EscalationStep(s))
+	if err != nil {
+		return nil, err
+	}
+	return string(b), nil
+}
+
+// Scan 实现 sql.Scanner
+func (s *EscalationSteps) Scan(value interface{}) error {
+	var data []byte
+	switch v := value.(type) {
+	case nil:
+		*s = nil
+		return nil
+	case string:
+		data = []byte(v)
+	case []byte:
+		data = v
+	default:
+		return fmt.Errorf("unsupported EscalationSteps value: %T", value)
+	}
+	return json.Unmarshal(data, (*[]EscalationStep)(s))
+}
+
+// EscalationPolicy 升级策略，资源剩余天数不超过 TriggerDays 时开始按升级链逐级通知
+type EscalationPolicy struct {
+	ID          uint            `gorm:"primaryKey" json:"id"`
+	UserID      uint            `gorm:"index;not null" json:"user_id"`
+	Name        string          `gorm:"not null" json:"name"`
+	TriggerDays int             `gorm:"not null" json:"trigger_days"`
+	Steps       EscalationSteps `gorm:"type:text" json:"steps"`
+	CreatedAt   time.Time       `json:"-"`
+}
+
+// EscalationState 资源在某个到期周期内的升级进度
+type EscalationState struct {
+	ID         uint       `gorm:"primaryKey"`
+	ResourceID uint       `gorm:"uniqueIndex:idx_escalation_state;not null"`
+	ExpireAt   int64      `gorm:"uniqueIndex:idx_escalation_state;not null"`
+	PolicyID   uint       `gorm:"index;not null"`
+	Step       int        `gorm:"not null"` // 已通知的级数
+	Status     string     `gorm:"index;not null"`
+	NextAt     *time.Time // 下一级的通知时间
+	StartedAt  time.Time
+	UpdatedAt  time.Time
+}
+
+// EscalationStateResponse 升级进度响应格式，时间使用 Unix 时间戳
+type EscalationStateResponse struct {
+	ID         uint   `json:"id"`
+	ResourceID uint   `json:"resource_id"`
+	ExpireAt   int64  `json:"expire_at"`
+	PolicyID   uint   `json:"policy_id"`
+	Step       int    `json:"step"`
+	Status     string `json:"status"`
+	NextAt     *int64 `json:"next_at"`
+	StartedAt  int64  `json:"started_at"`
+	UpdatedAt  int64  `json:"updated_at"`
+}
+
+// ToResponse 转换为响应格式
+func (s *EscalationState) ToResponse() EscalationStateResponse {
+	resp := EscalationStateResponse{
+		ID:         s.ID,
+		ResourceID: s.ResourceID,
+		ExpireAt:   s.ExpireAt,
+		PolicyID:   s.PolicyID,
+		Step:       s.Step,
+		Status:     s.Status,
+		StartedAt:  s.StartedAt.Unix(),
+		UpdatedAt:  s.UpdatedAt.Unix(),
+	}
+	if s.NextAt != nil {
+		ts := s.NextAt.Unix()
+		resp.NextAt = &ts
+	}
+	return resp
+}
@@ -0,0 +1,11 @@
+package models
+
+import "time"
+
+// ExchangeRate 手动维护的汇率，Rate 表示 1 单位 Currency 折合多少基准货币（BASE_CURRENCY）
+type ExchangeRate struct {
+	ID        uint      `gorm:"primaryKey" json:"-"`
+	Currency  string    `gorm:"uniqueIndex;not null" json:"currency"`
+	Rate      float64   `gorm:"not null" json:"rate"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
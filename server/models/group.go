@@ -0,0 +1,10 @@
+package models
+
+// GroupSetting 分组级别的提醒策略，组内未单独设置的资源继承该策略
+type GroupSetting struct {
+	ID                uint    `gorm:"primaryKey" json:"id"`
+	WorkspaceID       uint    `gorm:"uniqueIndex:idx_group_setting_workspace_name;not null;default:0" json:"-"`
+	Name              string  `gorm:"uniqueIndex:idx_group_setting_workspace_name;not null" json:"group"`
+	ReminderDays      IntList `gorm:"type:text" json:"reminder_days"` // 距离到期的提醒天数，null 表示使用全局默认
+	RepeatAfterExpiry *int    `json:"repeat_after_expiry"`            // 过期后每隔 N 天重复提醒，null 表示使用全局默认，0 表示不重复
+}
@@ -0,0 +1,21 @@
+package models
+
+import "time"
+
+// 登录失败原因
+const (
+	LoginReasonInvalidPassword = "invalid_password" // 用户不存在或密码错误
+	LoginReasonInvalidCode     = "invalid_code"     // 两步验证码或恢复码错误
+	LoginReasonDisabled        = "disabled"
+)
+
+// LoginAttempt 登录尝试记录，用于限流和审计
+type LoginAttempt struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	Username  string    `gorm:"index;not null" json:"username"`
+	IP        string    `gorm:"index;not null" json:"ip"`
+	UserAgent string    `json:"user_agent"`
+	Success   bool      `gorm:"not null" json:"success"`
+	Reason    string    `json:"reason"` // 失败原因
+	CreatedAt time.Time `gorm:"index" json:"created_at"`
+}
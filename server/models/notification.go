@@ -0,0 +1,267 @@
+package models
+
+import (
+	"encoding/json"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// NotificationChannel 通知渠道配置，Config 为渠道类型相关的 JSON 配置
+type NotificationChannel struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	UserID    uint      `gorm:"index;not null" json:"user_id"`
+	Name      string    `gorm:"not null" json:"name"`
+	Type      string    `gorm:"not null" json:"type"`
+	Enabled   bool      `gorm:"not null" json:"enabled"`
+	Language  string    `gorm:"not null;default:zh" json:"language"`
+	Config    string    `gorm:"type:text" json:"-"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+
+	// 免打扰时段（HH:MM，可跨越午夜），期间非紧急提醒延后到时段结束后发送
+	QuietStart    string `json:"quiet_start"`
+	QuietEnd      string `json:"quiet_end"`
+	QuietTimezone string `json:"quiet_timezone"`
+}
+
+// NotificationChannelResponse 渠道响应格式，时间使用 Unix 时间戳
+type NotificationChannelResponse struct {
+	ID        uint            `json:"id"`
+	Name      string          `json:"name"`
+	Type      string          `json:"type"`
+	Enabled   bool            `json:"enabled"`
+	Language  string          `json:"language"`
+	Config    json.RawMessage `json:"config"`
+	CreatedAt int64           `json:"created_at"`
+	UpdatedAt int64           `json:"updated_at"`
+
+	QuietStart    string `json:"quiet_start"`
+	QuietEnd      string `json:"quiet_end"`
+	QuietTimezone string `json:"quiet_timezone"`
+}
+
+// SecretMask 响应中代替密钥字段的占位符，更新渠道时原样提交表示保留原值
+const SecretMask = "********"
+
+// secretConfigFields 渠道配置中的密钥字段（SMTP / ntfy 密码、加签密钥、Token、Bark 设备 Key、Server 酱 SendKey）
+var secretConfigFields = []string{"password", "secret", "token", "device_key", "send_key"}
+
+// ToResponse 转换为响应格式，配置中的密钥字段替换为 SecretMask：
+// url 只保留协议和主机（机器人地址的路径和参数中含有 key / access_token），webhook 的 headers 隐藏全部取值
+func (n *NotificationChannel) ToResponse() NotificationChannelResponse {
+	config := json.RawMessage("{}")
+	if fields := configFields(n.Config); fields != nil {
+		maskConfig(fields)
+		if b, err := json.Marshal(fields); err == nil {
+			config = b
+		}
+	}
+
+	return NotificationChannelResponse{
+		ID:        n.ID,
+		Name:      n.Name,
+		Type:      n.Type,
+		Enabled:   n.Enabled,
+		Language:  n.Language,
+		Config:    config,
+		CreatedAt: n.CreatedAt.Unix(),
+		UpdatedAt: n.UpdatedAt.Unix(),
+
+		QuietStart:    n.QuietStart,
+		QuietEnd:      n.QuietEnd,
+		QuietTimezone: n.QuietTimezone,
+	}
+}
+
+// maskConfig 将配置中的密钥替换为 SecretMask
+func maskConfig(fields map[string]json.RawMessage) {
+	mask, _ := json.Marshal(SecretMask)
+	for _, name := range secretConfigFields {
+		var value string
+		if json.Unmarshal(fields[name], &value) == nil && value != "" {
+			fields[name] = mask
+		}
+	}
+
+	var rawURL string
+	if json.Unmarshal(fields["url"], &rawURL) == nil && rawURL != "" {
+		fields["url"], _ = json.Marshal(maskURL(rawURL))
+	}
+
+	var headers map[string]string
+	if json.Unmarshal(fields["headers"], &headers) == nil && len(headers) > 0 {
+		for k := range headers {
+			headers[k] = SecretMask
+		}
+		fields["headers"], _ = json.Marshal(headers)
+	}
+}
+
+// maskURL 只保留协议和主机，如 https://oapi.dingtalk.com/********，没有路径和参数的地址原样返回
+func maskURL(raw string) string {
+	u, err := url.Parse(raw)
+	if err != nil || u.Host == "" {
+		return SecretMask
+	}
+	if (u.Path == "" || u.Path == "/") && u.RawQuery == "" && u.User == nil {
+		return raw
+	}
+	return u.Scheme + "://" + u.Host + "/" + SecretMask
+}
+
+// RestoreSecretConfig 将 config 中原样提交的占位符还原为 previous 中的值，config 不是 JSON 对象时原样返回：
+// 值为 SecretMask 的密钥字段和请求头、与 previous 打码后相同的 url
+func RestoreSecretConfig(config, previous string) string {
+	fields := configFields(config)
+	if fields == nil {
+		return config
+	}
+	old := configFields(previous)
+
+	changed := false
+	for _, name := range secretConfigFields {
+		var value string
+		if json.Unmarshal(fields[name], &value) != nil || value != SecretMask {
+			continue
+		}
+		if v, ok := old[name]; ok {
+			fields[name] = v
+		} else {
+			delete(fields, name)
+		}
+		changed = true
+	}
+
+	var rawURL, oldURL string
+	if json.Unmarshal(fields["url"], &rawURL) == nil && strings.Contains(rawURL, SecretMask) &&
+		json.Unmarshal(old["url"], &oldURL) == nil && oldURL != "" && maskURL(oldURL) == rawURL {
+		fields["url"] = old["url"]
+		changed = true
+	}
+
+	var headers, oldHeaders map[string]string
+	if json.Unmarshal(fields["headers"], &headers) == nil && len(headers) > 0 {
+		json.Unmarshal(old["headers"], &oldHeaders)
+		restored := false
+		for k, v := range headers {
+			if v != SecretMask {
+				continue
+			}
+			if ov, ok := oldHeaders[k]; ok {
+				headers[k] = ov
+			} else {
+				delete(headers, k)
+			}
+			restored = true
+		}
+		if restored {
+			fields["headers"], _ = json.Marshal(headers)
+			changed = true
+		}
+	}
+
+	if !changed {
+		return config
+	}
+
+	b, err := json.Marshal(fields)
+	if err != nil {
+		return config
+	}
+	return string(b)
+}
+
+func configFields(config string) map[string]json.RawMessage {
+	var fields map[string]json.RawMessage
+	if json.Unmarshal([]byte(config), &fields) != nil {
+		return nil
+	}
+	return fields
+}
+
+// NotificationLog 已发送的提醒记录，用于重启后避免重复发送
+// ExpireAt 记录发送时资源的到期时间（Unix 时间戳），续约后到期时间变化即视为新的提醒周期
+type NotificationLog struct {
+	ID         uint      `gorm:"primaryKey" json:"id"`
+	ResourceID uint      `gorm:"uniqueIndex:idx_notification_log;not null" json:"resource_id"`
+	ChannelID  uint      `gorm:"uniqueIndex:idx_notification_log;not null" json:"channel_id"`
+	Threshold  int       `gorm:"uniqueIndex:idx_notification_log;not null" json:"threshold"`
+	ExpireAt   int64     `gorm:"uniqueIndex:idx_notification_log;not null" json:"expire_at"`
+	SentAt     time.Time `gorm:"not null" json:"sent_at"`
+}
+
+// NotificationLogResponse 发送记录响应格式
+type NotificationLogResponse struct {
+	ID         uint  `json:"id"`
+	ResourceID uint  `json:"resource_id"`
+	ChannelID  uint  `json:"channel_id"`
+	Threshold  int   `json:"threshold"`
+	ExpireAt   int64 `json:"expire_at"`
+	SentAt     int64 `json:"sent_at"`
+}
+
+// ToResponse 转换为响应格式
+func (l *NotificationLog) ToResponse() NotificationLogResponse {
+	return NotificationLogResponse{
+		ID:         l.ID,
+		ResourceID: l.ResourceID,
+		ChannelID:  l.ChannelID,
+		Threshold:  l.Threshold,
+		ExpireAt:   l.ExpireAt,
+		SentAt:     l.SentAt.Unix(),
+	}
+}
+
+// WebhookDelivery Webhook 投递记录，每次尝试（含重试）记录一条
+type WebhookDelivery struct {
+	ID           uint      `gorm:"primaryKey" json:"id"`
+	ChannelID    uint      `gorm:"index;not null" json:"channel_id"`
+	Event        string    `gorm:"not null" json:"event"`
+	ResourceID   uint      `json:"resource_id"`
+	URL          string    `gorm:"not null" json:"url"`
+	RequestBody  string    `gorm:"type:text" json:"request_body"`
+	StatusCode   int       `json:"status_code"`
+	ResponseBody string    `gorm:"type:text" json:"response_body"`
+	Attempt      int       `gorm:"not null" json:"attempt"`
+	Success      bool      `gorm:"not null" json:"success"`
+	Error        string    `json:"error"`
+	DurationMs   int64     `json:"duration_ms"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+// WebhookDeliveryResponse 投递记录响应格式
+type WebhookDeliveryResponse struct {
+	ID           uint   `json:"id"`
+	ChannelID    uint   `json:"channel_id"`
+	Event        string `json:"event"`
+	ResourceID   uint   `json:"resource_id"`
+	URL          string `json:"url"`
+	RequestBody  string `json:"request_body"`
+	StatusCode   int    `json:"status_code"`
+	ResponseBody string `json:"response_body"`
+	Attempt      int    `json:"attempt"`
+	Success      bool   `json:"success"`
+	Error        string `json:"error"`
+	DurationMs   int64  `json:"duration_ms"`
+	CreatedAt    int64  `json:"created_at"`
+}
+
+// ToResponse 转换为响应格式
+func (d *WebhookDelivery) ToResponse() WebhookDeliveryResponse {
+	return WebhookDeliveryResponse{
+		ID:           d.ID,
+		ChannelID:    d.ChannelID,
+		Event:        d.Event,
+		ResourceID:   d.ResourceID,
+		URL:          d.URL,
+		RequestBody:  d.RequestBody,
+		StatusCode:   d.StatusCode,
+		ResponseBody: d.ResponseBody,
+		Attempt:      d.Attempt,
+		Success:      d.Success,
+		Error:        d.Error,
+		DurationMs:   d.DurationMs,
+		CreatedAt:    d.CreatedAt.Unix(),
+	}
+}
@@ -0,0 +1,130 @@
+package models
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestNotificationChannelMasksSecrets(t *testing.T) {
+	channel := NotificationChannel{
+		Type:   "email",
+		Config: `{"host":"smtp.example.com","username":"tally","password":"hunter2","token":""}`,
+	}
+
+	var config map[string]string
+	if err := json.Unmarshal(channel.ToResponse().Config, &config); err != nil {
+		t.Fatalf("unmarshal config: %v", err)
+	}
+	if config["password"] != SecretMask {
+		t.Errorf("password = %q, want mask", config["password"])
+	}
+	if config["token"] != "" {
+		t.Errorf("empty token should stay empty, got %q", config["token"])
+	}
+	if config["host"] != "smtp.example.com" || config["username"] != "tally" {
+		t.Errorf("non-secret fields changed: %v", config)
+	}
+}
+
+func TestRestoreSecretConfig(t *testing.T) {
+	previous := `{"server":"https://ntfy.sh","token":"tk_old","password":"pw_old"}`
+
+	tests := []struct {
+		name   string
+		config string
+		want   map[string]string
+	}{
+		{
+			name:   "masked values are kept",
+			config: `{"server":"https://ntfy.example.com","token":"********","password":"********"}`,
+			want:   map[string]string{"server": "https://ntfy.example.com", "token": "tk_old", "password": "pw_old"},
+		},
+		{
+			name:   "new values replace old ones",
+			config: `{"server":"https://ntfy.sh","token":"tk_new"}`,
+			want:   map[string]string{"server": "https://ntfy.sh", "token": "tk_new"},
+		},
+		{
+			name:   "mask without previous value is dropped",
+			config: `{"secret":"********"}`,
+			want:   map[string]string{},
+		},
+	}
+	for _, tt := range tests {
+		var got map[string]string
+		if err := json.Unmarshal([]byte(RestoreSecretConfig(tt.config, previous)), &got); err != nil {
+			t.Fatalf("%s: unmarshal: %v", tt.name, err)
+		}
+		if len(got) != len(tt.want) {
+			t.Errorf("%s: got %v, want %v", tt.name, got, tt.want)
+			continue
+		}
+		for k, v := range tt.want {
+			if got[k] != v {
+				t.Errorf("%s: %s = %q, want %q", tt.name, k, got[k], v)
+			}
+		}
+	}
+
+	if got := RestoreSecretConfig("not json", previous); got != "not json" {
+		t.Errorf("invalid config should be returned unchanged, got %q", got)
+	}
+}
+
+func TestNotificationChannelMasksURLAndHeaders(t *testing.T) {
+	channel := NotificationChannel{
+		Type:   "webhook",
+		Config: `{"url":"https://hooks.example.com/services/T0/B0/xyz?key=abc","headers":{"Authorization":"Bearer abc","X-Api-Key":"k"}}`,
+	}
+
+	var config struct {
+		URL     string            `json:"url"`
+		Headers map[string]string `json:"headers"`
+	}
+	if err := json.Unmarshal(channel.ToResponse().Config, &config); err != nil {
+		t.Fatalf("unmarshal config: %v", err)
+	}
+	if config.URL != "https://hooks.example.com/"+SecretMask {
+		t.Errorf("url = %q", config.URL)
+	}
+	if len(config.Headers) != 2 || config.Headers["Authorization"] != SecretMask || config.Headers["X-Api-Key"] != SecretMask {
+		t.Errorf("headers = %v", config.Headers)
+	}
+
+	// 没有路径和参数的地址不含密钥，原样返回
+	plain := NotificationChannel{Type: "webhook", Config: `{"url":"https://example.com"}`}
+	if !strings.Contains(string(plain.ToResponse().Config), `"https://example.com"`) {
+		t.Errorf("plain url masked: %s", plain.ToResponse().Config)
+	}
+}
+
+func TestRestoreSecretConfigURLAndHeaders(t *testing.T) {
+	previous := `{"url":"https://oapi.dingtalk.com/robot/send?access_token=abc","headers":{"Authorization":"Bearer abc","X-Old":"1"}}`
+
+	var got struct {
+		URL     string            `json:"url"`
+		Headers map[string]string `json:"headers"`
+	}
+
+	// 原样提交打码后的配置，保留原值；未在 previous 中出现的打码请求头被丢弃
+	unchanged := `{"url":"https://oapi.dingtalk.com/********","headers":{"Authorization":"********","X-New":"********","X-Trace":"on"}}`
+	if err := json.Unmarshal([]byte(RestoreSecretConfig(unchanged, previous)), &got); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	if got.URL != "https://oapi.dingtalk.com/robot/send?access_token=abc" {
+		t.Errorf("url = %q", got.URL)
+	}
+	if len(got.Headers) != 2 || got.Headers["Authorization"] != "Bearer abc" || got.Headers["X-Trace"] != "on" {
+		t.Errorf("headers = %v", got.Headers)
+	}
+
+	// 修改了地址时使用新值
+	changed := `{"url":"https://oapi.dingtalk.com/robot/send?access_token=new"}`
+	if err := json.Unmarshal([]byte(RestoreSecretConfig(changed, previous)), &got); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	if got.URL != "https://oapi.dingtalk.com/robot/send?access_token=new" {
+		t.Errorf("url = %q", got.URL)
+	}
+}
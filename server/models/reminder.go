@@ -0,0 +1,117 @@
+package models
+
+import (
+	"sort"
+	"time"
+)
+
+// ReminderPolicy 生效的提醒策略
+type ReminderPolicy struct {
+	Days              []int `json:"reminder_days"`       // 距离到期的提醒天数，降序
+	RepeatAfterExpiry int   `json:"repeat_after_expiry"` // 过期后每隔 N 天重复提醒，0 表示不重复
+}
+
+// ResolveReminderPolicy 按 资源 → 分组 → 全局默认 的顺序确定生效的提醒策略
+// 两个字段分别继承，例如资源只设置了提醒天数时，重复间隔仍取自分组或全局
+func ResolveReminderPolicy(r *Resource, group *GroupSetting, defaults ReminderPolicy) ReminderPolicy {
+	policy := defaults
+	if group != nil {
+		if group.ReminderDays != nil {
+			policy.Days = group.ReminderDays
+		}
+		if group.RepeatAfterExpiry != nil {
+			policy.RepeatAfterExpiry = *group.RepeatAfterExpiry
+		}
+	}
+	if r.ReminderDays != nil {
+		policy.Days = r.ReminderDays
+	}
+	if r.RepeatAfterExpiry != nil {
+		policy.RepeatAfterExpiry = *r.RepeatAfterExpiry
+	}
+
+	policy.Days = NormalizeReminderDays(policy.Days)
+	return policy
+}
+
+// Threshold 返回剩余天数当前所处的提醒阈值
+// 未过期或未开启重复时返回已跨越的最小提醒天数，例如 [30 7 1] 剩余 5 天时返回 7；
+// 过期且开启重复时返回负数的过期天数档位，例如每 7 天重复、已过期 15 天时返回 -14
+func (p ReminderPolicy) Threshold(remainingDays int) (int, bool) {
+	if remainingDays < 0 && p.RepeatAfterExpiry > 0 {
+		overdue := -remainingDays
+		if overdue >= p.RepeatAfterExpiry {
+			return -(overdue / p.RepeatAfterExpiry * p.RepeatAfterExpiry), true
+		}
+	}
+
+	threshold, ok := 0, false
+	for _, d := range p.Days {
+		if remainingDays <= d {
+			threshold, ok = d, true
+		}
+	}
+	return threshold, ok
+}
+
+// NormalizeReminderDays 去重并降序排列，保留 nil 与空列表的区别
+func NormalizeReminderDays(days []int) []int {
+	if days == nil {
+		return nil
+	}
+
+	seen := make(map[int]bool, len(days))
+	result := make([]int, 0, len(days))
+	for _, d := range days {
+		if !seen[d] {
+			seen[d] = true
+			result = append(result, d)
+		}
+	}
+	sort.Sort(sort.Reverse(sort.IntSlice(result)))
+	return result
+}
+
+// ReminderAck 资源在某个提醒周期内的确认 / 暂停状态
+// ExpireAt 为资源当时的到期时间（Unix 时间戳），续约后到期时间变化即进入新的周期
+type ReminderAck struct {
+	ID             uint       `gorm:"primaryKey" json:"id"`
+	ResourceID     uint       `gorm:"uniqueIndex:idx_reminder_ack;not null" json:"resource_id"`
+	ExpireAt       int64      `gorm:"uniqueIndex:idx_reminder_ack;not null" json:"expire_at"`
+	AcknowledgedAt *time.Time `json:"acknowledged_at"`
+	AcknowledgedBy uint       `json:"acknowledged_by"`
+	SnoozedUntil   *time.Time `json:"snoozed_until"`
+	UpdatedAt      time.Time  `json:"updated_at"`
+}
+
+// Silenced 判断提醒当前是否被确认或暂停
+func (a *ReminderAck) Silenced(now time.Time) bool {
+	return a.AcknowledgedAt != nil || (a.SnoozedUntil != nil && a.SnoozedUntil.After(now))
+}
+
+// ReminderStateResponse 资源当前周期的提醒状态，时间使用 Unix 时间戳
+type ReminderStateResponse struct {
+	ResourceID     uint   `json:"resource_id"`
+	ExpireAt       int64  `json:"expire_at"`
+	Acknowledged   bool   `json:"acknowledged"`
+	AcknowledgedAt *int64 `json:"acknowledged_at"`
+	SnoozedUntil   *int64 `json:"snoozed_until"`
+}
+
+// ToResponse 转换为响应格式，已过期的暂停不再输出
+func (a *ReminderAck) ToResponse() ReminderStateResponse {
+	resp := ReminderStateResponse{
+		ResourceID:   a.ResourceID,
+		ExpireAt:     a.ExpireAt,
+		Acknowledged: a.AcknowledgedAt != nil,
+	}
+	if a.AcknowledgedAt != nil {
+		ts := a.AcknowledgedAt.Unix()
+		resp.AcknowledgedAt = &ts
+	}
+	if a.SnoozedUntil != nil && a.SnoozedUntil.After(time.Now()) {
+		ts := a.SnoozedUntil.Unix()
+		resp.SnoozedUntil = &ts
+	}
+	return resp
+}
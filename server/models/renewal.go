@@ -0,0 +1,86 @@
+package models
+
+import (
+	"math"
+	"time"
+)
+
+// Renewal 续约记录，保留每次续约前后的到期时间，可撤销最近一次续约，也用于统计历史支出
+type Renewal struct {
+	ID          uint       `gorm:"primaryKey" json:"id"`
+	ResourceID  uint       `gorm:"index;not null" json:"resource_id"`
+	WorkspaceID uint       `gorm:"index;not null" json:"workspace_id"`
+	UserID      uint       `gorm:"index;not null" json:"user_id"`      // 操作者，自动续费为 0
+	Username    string     `json:"username"`                           // 操作时的用户名
+	Auto        bool       `gorm:"not null;default:false" json:"auto"` // 由后台任务按计费周期自动续费
+	GroupName   string     `json:"group"`                              // 续约时资源所在分组
+	OldExpireAt time.Time  `json:"old_expire_at"`
+	NewExpireAt time.Time  `json:"new_expire_at"`
+	DaysAdded   int        `gorm:"not null;default:0" json:"days_added"`
+	Cost        *float64   `json:"cost"` // 为 null 时不计入支出
+	Currency    string     `json:"currency"`
+	Note        string     `json:"note"`
+	CreatedAt   time.Time  `gorm:"index" json:"created_at"`
+	UndoneAt    *time.Time `json:"undone_at"` // 撤销后不再计入支出
+}
+
+// RenewalResponse 续约记录，时间使用 Unix 时间戳
+type RenewalResponse struct {
+	ID          uint     `json:"id"`
+	ResourceID  uint     `json:"resource_id"`
+	UserID      uint     `json:"user_id"`
+	Username    string   `json:"username"`
+	Auto        bool     `json:"auto"`
+	OldExpireAt int64    `json:"old_expire_at"`
+	NewExpireAt int64    `json:"new_expire_at"`
+	DaysAdded   int      `json:"days_added"`
+	Cost        *float64 `json:"cost"`
+	Currency    string   `json:"currency"`
+	Note        string   `json:"note"`
+	CreatedAt   int64    `json:"created_at"`
+	UndoneAt    *int64   `json:"undone_at"`
+}
+
+// ToResponse 转换为响应格式
+func (r *Renewal) ToResponse() RenewalResponse {
+	resp := RenewalResponse{
+		ID:          r.ID,
+		ResourceID:  r.ResourceID,
+		UserID:      r.UserID,
+		Username:    r.Username,
+		Auto:        r.Auto,
+		OldExpireAt: r.OldExpireAt.Unix(),
+		NewExpireAt: r.NewExpireAt.Unix(),
+		DaysAdded:   r.DaysAdded,
+		Cost:        r.Cost,
+		Currency:    r.Currency,
+		Note:        r.Note,
+		CreatedAt:   r.CreatedAt.Unix(),
+	}
+	if r.UndoneAt != nil {
+		undoneAt := r.UndoneAt.Unix()
+		resp.UndoneAt = &undoneAt
+	}
+	return resp
+}
+
+// RenewalDays 续约延长的天数，四舍五入到整天
+func RenewalDays(oldExpireAt, newExpireAt time.Time) int {
+	return int(math.Round(newExpireAt.Sub(oldExpireAt).Hours() / 24))
+}
+
+// EstimateRenewalCost 按资源价格估算从 from 续约到 to 的费用：周期性计费按延长的时间折算周期数（至少 1 个周期），
+// 未设置计费周期或一次性付费按一次价格计算；未设置价格或没有延长时返回 nil
+func EstimateRenewalCost(r *Resource, from, to time.Time) *float64 {
+	if r.Price == nil || !to.After(from) {
+		return nil
+	}
+
+	cycles := 1.0
+	if cycleDays := billingCycleDays(r.BillingCycle, r.BillingDays); cycleDays > 0 {
+		days := to.Sub(from).Hours() / 24
+		cycles = math.Max(1, math.Round(days/cycleDays))
+	}
+	cost := RoundMoney(*r.Price * cycles)
+	return &cost
+}
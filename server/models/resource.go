@@ -7,20 +7,53 @@ import (
 
 type Resource struct {
 	ID        uint      `gorm:"primaryKey" json:"id"`
+	UserID    uint      `gorm:"index;not null;default:0" json:"user_id"` // 创建者
 	Name      string    `gorm:"not null" json:"name"`
 	GroupName string    `gorm:"column:group_name;index" json:"group"`
 	ExpireAt  time.Time `gorm:"not null" json:"expire_at"`
 	CreatedAt time.Time `json:"created_at"`
+
+	// 所属工作区，访问权限按工作区成员角色判断
+	WorkspaceID uint `gorm:"index;not null;default:0" json:"workspace_id"`
+
+	// 提醒策略，为 null 时继承分组设置或全局默认
+	ReminderDays      IntList `gorm:"type:text" json:"reminder_days"`
+	RepeatAfterExpiry *int    `json:"repeat_after_expiry"`
+
+	// 升级策略，为 null 时不升级
+	EscalationPolicyID *uint `gorm:"index" json:"escalation_policy_id"`
+
+	// 费用，Price 为 null 时未设置；BillingDays 仅用于 custom 计费周期
+	Price        *float64 `json:"price"`
+	Currency     string   `json:"currency"` // ISO 4217 代码
+	BillingCycle string   `json:"billing_cycle"`
+	BillingDays  int      `gorm:"not null;default:0" json:"billing_days"`
+	AutoRenew    bool     `gorm:"not null;default:false" json:"auto_renew"` // 到期时自动扣费续期
 }
 
 // ResourceResponse 包含计算后的剩余天数，时间使用 Unix 时间戳
 type ResourceResponse struct {
-	ID            uint   `json:"id"`
-	Name          string `json:"name"`
-	GroupName     string `json:"group"`
-	ExpireAt      int64  `json:"expire_at"`
-	CreatedAt     int64  `json:"created_at"`
-	RemainingDays int    `json:"remaining_days"`
+	ID                uint    `json:"id"`
+	WorkspaceID       uint    `json:"workspace_id"`
+	Name              string  `json:"name"`
+	GroupName         string  `json:"group"`
+	ExpireAt          int64   `json:"expire_at"`
+	CreatedAt         int64   `json:"created_at"`
+	RemainingDays     int     `json:"remaining_days"`
+	ReminderDays      IntList `json:"reminder_days"`
+	RepeatAfterExpiry *int    `json:"repeat_after_expiry"`
+
+	EscalationPolicyID *uint `json:"escalation_policy_id"`
+
+	Price        *float64 `json:"price"`
+	Currency     string   `json:"currency"`
+	BillingCycle string   `json:"billing_cycle"`
+	BillingDays  int      `json:"billing_days"`
+	AutoRenew    bool     `json:"auto_renew"`
+
+	// 当前提醒周期的确认 / 暂停状态，仅资源列表接口填充
+	Acknowledged bool   `json:"acknowledged"`
+	SnoozedUntil *int64 `json:"snoozed_until,omitempty"`
 }
 
 // ToResponse 转换为响应格式，计算剩余天数，时间转为 Unix 时间戳
@@ -30,11 +63,22 @@ func (r *Resource) ToResponse() ResourceResponse {
 	days := int(math.Ceil(duration.Hours() / 24))
 
 	return ResourceResponse{
-		ID:            r.ID,
-		Name:          r.Name,
-		GroupName:     r.GroupName,
-		ExpireAt:      r.ExpireAt.Unix(),
-		CreatedAt:     r.CreatedAt.Unix(),
-		RemainingDays: days,
+		ID:                r.ID,
+		WorkspaceID:       r.WorkspaceID,
+		Name:              r.Name,
+		GroupName:         r.GroupName,
+		ExpireAt:          r.ExpireAt.Unix(),
+		CreatedAt:         r.CreatedAt.Unix(),
+		RemainingDays:     days,
+		ReminderDays:      r.ReminderDays,
+		RepeatAfterExpiry: r.RepeatAfterExpiry,
+
+		EscalationPolicyID: r.EscalationPolicyID,
+
+		Price:        r.Price,
+		Currency:     r.Currency,
+		BillingCycle: r.BillingCycle,
+		BillingDays:  r.BillingDays,
+		AutoRenew:    r.AutoRenew,
 	}
 }
@@ -0,0 +1,42 @@
+package models
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"time"
+)
+
+// Session 登录会话，保存刷新令牌的哈希，access token 通过 sid 关联会话，撤销后立即失效
+type Session struct {
+	ID           uint       `gorm:"primaryKey" json:"id"`
+	UserID       uint       `gorm:"index;not null" json:"-"`
+	TokenHash    string     `gorm:"uniqueIndex;not null" json:"-"` // 当前刷新令牌
+	PreviousHash string     `gorm:"index" json:"-"`                // 上一个刷新令牌，用于发现被盗用的旧令牌
+	RotatedAt    time.Time  `json:"-"`
+	UserAgent    string     `json:"user_agent"`
+	IP           string     `json:"ip"`
+	CreatedAt    time.Time  `json:"created_at"`
+	LastUsedAt   time.Time  `json:"last_used_at"`
+	ExpiresAt    time.Time  `json:"expires_at"`
+	RevokedAt    *time.Time `json:"-"`
+}
+
+// Active 判断会话在 now 时刻是否有效
+func (s *Session) Active(now time.Time) bool {
+	return s.RevokedAt == nil && now.Before(s.ExpiresAt)
+}
+
+// SessionResponse 会话列表项
+type SessionResponse struct {
+	Session
+	Current bool `json:"current"` // 是否为发起请求的会话
+}
+
+// GenerateRefreshToken 生成刷新令牌明文，与 API Token 一样只保存 SHA-256 哈希
+func GenerateRefreshToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
@@ -0,0 +1,64 @@
+package models
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"strings"
+	"time"
+)
+
+// APITokenPrefix API Token 前缀，用于与 JWT 区分
+const APITokenPrefix = "tly_"
+
+// API Token 权限范围
+const (
+	ScopeRead      = "read"       // 只读
+	ScopeReadWrite = "read-write" // 读写
+	ScopeBackup    = "backup"     // 仅导出 / 还原备份
+)
+
+// IsValidScope 判断权限范围是否合法
+func IsValidScope(scope string) bool {
+	return scope == ScopeRead || scope == ScopeReadWrite || scope == ScopeBackup
+}
+
+// APIToken 个人 API Token，只保存哈希
+type APIToken struct {
+	ID         uint       `gorm:"primaryKey" json:"id"`
+	UserID     uint       `gorm:"index;not null" json:"-"`
+	Name       string     `gorm:"not null" json:"name"`
+	Prefix     string     `gorm:"not null" json:"prefix"` // 明文前几位，便于识别
+	TokenHash  string     `gorm:"uniqueIndex;not null" json:"-"`
+	Scope      string     `gorm:"not null" json:"scope"`
+	ExpiresAt  *time.Time `json:"expires_at"`
+	LastUsedAt *time.Time `json:"last_used_at"`
+	RevokedAt  *time.Time `json:"revoked_at"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+// Active 判断 Token 在 now 时刻是否可用
+func (t *APIToken) Active(now time.Time) bool {
+	return t.RevokedAt == nil && (t.ExpiresAt == nil || now.Before(*t.ExpiresAt))
+}
+
+// GenerateAPIToken 生成新的 Token 明文
+func GenerateAPIToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return APITokenPrefix + base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// HashAPIToken 计算 Token 哈希，Token 为高熵随机串，SHA-256 即可
+func HashAPIToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// IsAPIToken 判断凭证是否为 API Token
+func IsAPIToken(credential string) bool {
+	return strings.HasPrefix(credential, APITokenPrefix)
+}
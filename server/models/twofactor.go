@@ -0,0 +1,38 @@
+package models
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+	"time"
+)
+
+// RecoveryCodeCount 每次生成的恢复码数量
+const RecoveryCodeCount = 10
+
+// RecoveryCode 两步验证恢复码，只保存哈希，每个只能使用一次
+type RecoveryCode struct {
+	ID        uint   `gorm:"primaryKey"`
+	UserID    uint   `gorm:"index;not null"`
+	CodeHash  string `gorm:"index;not null"`
+	UsedAt    *time.Time
+	CreatedAt time.Time
+}
+
+// GenerateRecoveryCode 生成形如 xxxx-xxxx-xxxx 的恢复码
+func GenerateRecoveryCode() (string, error) {
+	b := make([]byte, 6)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	s := hex.EncodeToString(b)
+	return s[0:4] + "-" + s[4:8] + "-" + s[8:12], nil
+}
+
+// HashRecoveryCode 忽略大小写、空格和连字符后计算哈希
+func HashRecoveryCode(code string) string {
+	normalized := strings.NewReplacer("-", "", " ", "").Replace(strings.ToLower(strings.TrimSpace(code)))
+	sum := sha256.Sum256([]byte(normalized))
+	return hex.EncodeToString(sum[:])
+}
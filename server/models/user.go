@@ -1,10 +1,59 @@
 package models
 
-import "time"
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// 用户角色
+const (
+	RoleAdmin = "admin" // 可管理所有用户
+	RoleUser  = "user"
+)
 
 type User struct {
 	ID        uint      `gorm:"primaryKey" json:"id"`
 	Username  string    `gorm:"uniqueIndex;not null" json:"username"`
 	Password  string    `gorm:"not null" json:"-"` // json:"-" 不输出密码
+	Role      string    `gorm:"not null;default:user" json:"role"`
+	Disabled  bool      `gorm:"not null;default:false" json:"disabled"` // 禁用后无法登录，已签发的 token 立即失效
 	CreatedAt time.Time `json:"created_at"`
+
+	// 修改密码前不允许进行其他操作（首次启动生成的初始管理员）
+	MustChangePassword bool `gorm:"not null;default:false" json:"must_change_password"`
+
+	// 两步验证，TOTPSecret 在启用前为待验证的密钥
+	TOTPEnabled  bool   `gorm:"not null;default:false" json:"totp_enabled"`
+	TOTPSecret   string `json:"-"`
+	TOTPLastStep int64  `json:"-"` // 最近一次验证成功的时间步长，防止验证码重放
+
+	// 连续登录失败次数，达到上限后锁定到 LockedUntil
+	FailedLogins int        `gorm:"not null;default:0" json:"failed_logins"`
+	LockedUntil  *time.Time `json:"locked_until"`
+
+	// OIDCSubject 绑定的单点登录账号（ID Token 中的 sub），本地账号为空
+	// 唯一索引在 database 中单独创建，SQLite 不支持为已有表添加 UNIQUE 列
+	OIDCSubject *string `gorm:"column:oidc_subject" json:"-"`
+}
+
+// IsAdmin 是否为管理员
+func (u *User) IsAdmin() bool {
+	return u.Role == RoleAdmin
+}
+
+// IsValidRole 判断角色是否合法
+func IsValidRole(role string) bool {
+	return role == RoleAdmin || role == RoleUser
+}
+
+// AfterCreate 新用户自动获得个人工作区
+func (u *User) AfterCreate(tx *gorm.DB) error {
+	_, err := CreatePersonalWorkspace(tx, u)
+	return err
+}
+
+// Locked 账户在 now 时刻是否处于登录锁定状态
+func (u *User) Locked(now time.Time) bool {
+	return u.LockedUntil != nil && now.Before(*u.LockedUntil)
 }
@@ -0,0 +1,86 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// 工作区成员角色，权限依次递增
+const (
+	WorkspaceViewer = "viewer" // 只读
+	WorkspaceEditor = "editor" // 可增删改资源
+	WorkspaceAdmin  = "admin"  // 可管理工作区和成员
+)
+
+var workspaceRoleRank = map[string]int{
+	WorkspaceViewer: 1,
+	WorkspaceEditor: 2,
+	WorkspaceAdmin:  3,
+}
+
+// IsValidWorkspaceRole 判断工作区角色是否合法
+func IsValidWorkspaceRole(role string) bool {
+	return workspaceRoleRank[role] > 0
+}
+
+// WorkspaceRoleAtLeast 判断 role 的权限是否不低于 required
+func WorkspaceRoleAtLeast(role, required string) bool {
+	return workspaceRoleRank[role] >= workspaceRoleRank[required] && workspaceRoleRank[role] > 0
+}
+
+// Workspace 工作区，资源归属于工作区。每个用户都有一个个人工作区
+type Workspace struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	Name      string    `gorm:"not null" json:"name"`
+	OwnerID   *uint     `gorm:"uniqueIndex" json:"-"` // 个人工作区的所有者，共享工作区为 null
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Personal 是否为个人工作区
+func (w *Workspace) Personal() bool {
+	return w.OwnerID != nil
+}
+
+// WorkspaceMember 工作区成员及其角色
+type WorkspaceMember struct {
+	ID          uint      `gorm:"primaryKey" json:"-"`
+	WorkspaceID uint      `gorm:"uniqueIndex:idx_workspace_member;not null" json:"workspace_id"`
+	UserID      uint      `gorm:"uniqueIndex:idx_workspace_member;index;not null" json:"user_id"`
+	Role        string    `gorm:"not null" json:"role"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// WorkspaceResponse 工作区及当前用户在其中的角色
+type WorkspaceResponse struct {
+	ID        uint   `json:"id"`
+	Name      string `json:"name"`
+	Personal  bool   `json:"personal"`
+	Role      string `json:"role"`
+	CreatedAt int64  `json:"created_at"`
+}
+
+// ToResponse 转换为响应格式
+func (w *Workspace) ToResponse(role string) WorkspaceResponse {
+	return WorkspaceResponse{
+		ID:        w.ID,
+		Name:      w.Name,
+		Personal:  w.Personal(),
+		Role:      role,
+		CreatedAt: w.CreatedAt.Unix(),
+	}
+}
+
+// CreatePersonalWorkspace 为用户创建个人工作区并设为管理员，已存在时直接返回
+func CreatePersonalWorkspace(tx *gorm.DB, user *User) (*Workspace, error) {
+	workspace := Workspace{Name: user.Username, OwnerID: &user.ID}
+	if err := tx.Where("owner_id = ?", user.ID).FirstOrCreate(&workspace).Error; err != nil {
+		return nil, err
+	}
+
+	member := WorkspaceMember{WorkspaceID: workspace.ID, UserID: user.ID, Role: WorkspaceAdmin}
+	if err := tx.Where("workspace_id = ? AND user_id = ?", workspace.ID, user.ID).FirstOrCreate(&member).Error; err != nil {
+		return nil, err
+	}
+	return &workspace, nil
+}
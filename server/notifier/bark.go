@@ -0,0 +1,84 @@
+package notifier
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"tally/models"
+)
+
+// barkConfig Bark 推送配置
+type barkConfig struct {
+	Server    string `json:"server"` // 默认 https://api.day.app
+	DeviceKey string `json:"device_key"`
+	Group     string `json:"group"` // 通知分组，默认 Tally
+	Sound     string `json:"sound"`
+}
+
+type barkNotifier struct {
+	config barkConfig
+}
+
+// barkLevels Bark 中断级别，critical 会忽略静音与勿扰模式
+var barkLevels = map[Priority]string{
+	PriorityLow:     "passive",
+	PriorityDefault: "active",
+	PriorityHigh:    "timeSensitive",
+	PriorityUrgent:  "critical",
+}
+
+func init() {
+	Register("bark", func(channel *models.NotificationChannel) (Notifier, error) {
+		var cfg barkConfig
+		if err := decodeConfig(channel, &cfg); err != nil {
+			return nil, err
+		}
+		if cfg.Server == "" {
+			cfg.Server = "https://api.day.app"
+		}
+		cfg.Server = strings.TrimRight(cfg.Server, "/")
+		if err := validateURL(cfg.Server); err != nil {
+			return nil, fmt.Errorf("bark: server %w", err)
+		}
+		if cfg.DeviceKey == "" {
+			return nil, errors.New("bark: device_key is required")
+		}
+		if cfg.Group == "" {
+			cfg.Group = "Tally"
+		}
+		return &barkNotifier{config: cfg}, nil
+	})
+}
+
+func (n *barkNotifier) Send(ctx context.Context, msg Message) error {
+	payload := map[string]interface{}{
+		"device_key": n.config.DeviceKey,
+		"title":      Title(msg),
+		"body":       PlainText(msg),
+		"level":      barkLevels[priorityOf(msg)],
+		"group":      n.config.Group,
+	}
+	if n.config.Sound != "" {
+		payload["sound"] = n.config.Sound
+	}
+
+	body, err := postJSON(ctx, n.config.Server+"/push", payload, nil)
+	if err != nil {
+		return fmt.Errorf("bark: %w", err)
+	}
+
+	var result struct {
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+	}
+	if err := json.Unmarshal(body, &result); err != nil {
+		return fmt.Errorf("bark: invalid response: %w", err)
+	}
+	if result.Code != 200 {
+		return fmt.Errorf("bark: code %d: %s", result.Code, result.Message)
+	}
+	return nil
+}
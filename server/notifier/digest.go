@@ -0,0 +1,165 @@
+package notifier
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"tally/models"
+)
+
+// 摘要频率
+const (
+	DigestDaily  = "daily"
+	DigestWeekly = "weekly"
+)
+
+// 摘要分区，按剩余天数划分
+const (
+	SectionExpired   = "expired"    // 已过期或今天到期
+	SectionThisWeek  = "this_week"  // 7 天内到期
+	SectionThisMonth = "this_month" // 30 天内到期
+)
+
+// Digest 到期摘要，分区内按分组归类
+type Digest struct {
+	Frequency string          `json:"frequency"`
+	Sections  []DigestSection `json:"sections"`
+}
+
+// DigestSection 摘要分区
+type DigestSection struct {
+	Key    string        `json:"key"`
+	Groups []DigestGroup `json:"groups"`
+}
+
+// DigestGroup 分区内的一个分组，资源按到期时间升序排列
+type DigestGroup struct {
+	GroupName string                    `json:"group"`
+	Resources []models.ResourceResponse `json:"resources"`
+}
+
+// digestSectionOf 根据剩余天数确定分区，30 天以后到期的资源不进入摘要
+func digestSectionOf(days int) (string, bool) {
+	switch {
+	case days <= 0:
+		return SectionExpired, true
+	case days <= 7:
+		return SectionThisWeek, true
+	case days <= 30:
+		return SectionThisMonth, true
+	default:
+		return "", false
+	}
+}
+
+// BuildDigest 将资源归入各分区，空分区不输出
+func BuildDigest(frequency string, resources []models.ResourceResponse) *Digest {
+	buckets := map[string]map[string][]models.ResourceResponse{}
+	for _, r := range sortedResources(resources) {
+		key, ok := digestSectionOf(r.RemainingDays)
+		if !ok {
+			continue
+		}
+		if buckets[key] == nil {
+			buckets[key] = map[string][]models.ResourceResponse{}
+		}
+		buckets[key][r.GroupName] = append(buckets[key][r.GroupName], r)
+	}
+
+	digest := &Digest{Frequency: frequency, Sections: []DigestSection{}}
+	for _, key := range []string{SectionExpired, SectionThisWeek, SectionThisMonth} {
+		groups, ok := buckets[key]
+		if !ok {
+			continue
+		}
+
+		names := make([]string, 0, len(groups))
+		for name := range groups {
+			names = append(names, name)
+		}
+		// 分组按名称排序，未分组放在最后
+		sort.Slice(names, func(i, j int) bool {
+			if names[i] == "" || names[j] == "" {
+				return names[j] == ""
+			}
+			return names[i] < names[j]
+		})
+
+		section := DigestSection{Key: key}
+		for _, name := range names {
+			section.Groups = append(section.Groups, DigestGroup{GroupName: name, Resources: groups[name]})
+		}
+		digest.Sections = append(digest.Sections, section)
+	}
+	return digest
+}
+
+// Count 摘要中的资源总数
+func (d *Digest) Count() int {
+	count := 0
+	for _, s := range d.Sections {
+		for _, g := range s.Groups {
+			count += len(g.Resources)
+		}
+	}
+	return count
+}
+
+// Resources 摘要中的全部资源，按到期时间升序排列
+func (d *Digest) Resources() []models.ResourceResponse {
+	var resources []models.ResourceResponse
+	for _, s := range d.Sections {
+		for _, g := range s.Groups {
+			resources = append(resources, g.Resources...)
+		}
+	}
+	return sortedResources(resources)
+}
+
+// sectionTitle 分区标题
+func sectionTitle(lang, key string) string {
+	return t(lang, "section_"+key)
+}
+
+// digestGroupName 分组名称，空分组显示为“未分组”
+func digestGroupName(lang, name string) string {
+	if name == "" {
+		return t(lang, "noGroup")
+	}
+	return name
+}
+
+// digestPlainText 纯文本格式的摘要正文
+func digestPlainText(msg Message) string {
+	var b strings.Builder
+	b.WriteString(intro(msg))
+	b.WriteString("\n")
+	for _, s := range msg.Digest.Sections {
+		fmt.Fprintf(&b, "\n[%s]\n", sectionTitle(msg.Language, s.Key))
+		for _, g := range s.Groups {
+			fmt.Fprintf(&b, "%s:\n", digestGroupName(msg.Language, g.GroupName))
+			for _, r := range g.Resources {
+				fmt.Fprintf(&b, "  - %s %s (%s)\n", r.Name, dateText(r), remainingText(msg.Language, r.RemainingDays))
+			}
+		}
+	}
+	return b.String()
+}
+
+// digestMarkdown Markdown 格式的摘要正文
+func digestMarkdown(msg Message) string {
+	var b strings.Builder
+	b.WriteString(intro(msg))
+	b.WriteString("\n")
+	for _, s := range msg.Digest.Sections {
+		fmt.Fprintf(&b, "\n#### %s\n", sectionTitle(msg.Language, s.Key))
+		for _, g := range s.Groups {
+			fmt.Fprintf(&b, "**%s**\n", digestGroupName(msg.Language, g.GroupName))
+			for _, r := range g.Resources {
+				fmt.Fprintf(&b, "- %s %s (%s)\n", r.Name, dateText(r), remainingText(msg.Language, r.RemainingDays))
+			}
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,118 @@
+package notifier
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"tally/models"
+)
+
+// dingtalkConfig 钉钉群机器人配置
+type dingtalkConfig struct {
+	URL       string   `json:"url"`        // 机器人 Webhook 地址（含 access_token）
+	Secret    string   `json:"secret"`     // 加签密钥（SEC 开头），为空表示未开启加签
+	AtMobiles []string `json:"at_mobiles"` // 需要 @ 的手机号
+	AtAll     bool     `json:"at_all"`
+}
+
+type dingtalkNotifier struct {
+	config dingtalkConfig
+}
+
+func init() {
+	Register("dingtalk", func(channel *models.NotificationChannel) (Notifier, error) {
+		var cfg dingtalkConfig
+		if err := decodeConfig(channel, &cfg); err != nil {
+			return nil, err
+		}
+		if err := validateURL(cfg.URL); err != nil {
+			return nil, fmt.Errorf("dingtalk: %w", err)
+		}
+		return &dingtalkNotifier{config: cfg}, nil
+	})
+}
+
+func (n *dingtalkNotifier) Send(ctx context.Context, msg Message) error {
+	title := Title(msg)
+	text := "### " + title + "\n\n" + Markdown(msg)
+	// 钉钉要求被 @ 的手机号出现在正文中
+	for _, mobile := range n.config.AtMobiles {
+		text += "\n@" + mobile
+	}
+
+	payload := map[string]interface{}{
+		"msgtype": "markdown",
+		"markdown": map[string]string{
+			"title": title,
+			"text":  text,
+		},
+		"at": map[string]interface{}{
+			"atMobiles": n.config.AtMobiles,
+			"isAtAll":   n.config.AtAll,
+		},
+	}
+
+	target := n.config.URL
+	if n.config.Secret != "" {
+		timestamp := strconv.FormatInt(time.Now().UnixMilli(), 10)
+		target = appendQuery(target, url.Values{
+			"timestamp": {timestamp},
+			"sign":      {dingtalkSign(n.config.Secret, timestamp)},
+		})
+	}
+
+	body, err := postJSON(ctx, target, payload, nil)
+	if err != nil {
+		return fmt.Errorf("dingtalk: %w", err)
+	}
+	return checkErrcode("dingtalk", body)
+}
+
+// dingtalkSign 钉钉加签：Base64(HMAC-SHA256(secret, timestamp + "\n" + secret))
+func dingtalkSign(secret, timestamp string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp + "\n" + secret))
+	return base64.StdEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// checkErrcode 检查钉钉、企业微信返回的 errcode
+func checkErrcode(channel string, body []byte) error {
+	var result struct {
+		Errcode int    `json:"errcode"`
+		Errmsg  string `json:"errmsg"`
+	}
+	if err := json.Unmarshal(body, &result); err != nil {
+		return fmt.Errorf("%s: invalid response: %w", channel, err)
+	}
+	if result.Errcode != 0 {
+		return fmt.Errorf("%s: errcode %d: %s", channel, result.Errcode, result.Errmsg)
+	}
+	return nil
+}
+
+// validateURL 校验机器人地址为 http(s) 绝对地址
+func validateURL(raw string) error {
+	u, err := url.Parse(raw)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return errors.New("url must be an absolute http(s) URL")
+	}
+	return nil
+}
+
+// appendQuery 在已有查询参数的地址后追加参数
+func appendQuery(raw string, values url.Values) string {
+	sep := "?"
+	if strings.Contains(raw, "?") {
+		sep = "&"
+	}
+	return raw + sep + values.Encode()
+}
@@ -0,0 +1,101 @@
+package notifier
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"tally/database"
+	"tally/models"
+)
+
+// SendTimeout 单次发送的超时时间（包含渠道内部的重试），按资源逐条投递的渠道对每次投递分别计时
+const SendTimeout = 2 * time.Minute
+
+// ErrNotSubscribed 渠道未订阅该事件，调用方应视为跳过而非失败
+var ErrNotSubscribed = errors.New("channel is not subscribed to this event")
+
+// accepts 判断渠道是否接收该事件
+func accepts(n Notifier, event Event) bool {
+	if event == EventTest {
+		return true
+	}
+	if f, ok := n.(EventFilter); ok {
+		return f.Accepts(event)
+	}
+	return event == EventReminder || event == EventDigest || event == EventEscalation
+}
+
+// Send 按渠道配置创建 Notifier 并发送消息
+func Send(ctx context.Context, channel *models.NotificationChannel, msg Message) error {
+	n, err := New(channel)
+	if err != nil {
+		return err
+	}
+
+	if !accepts(n, msg.Event) {
+		return ErrNotSubscribed
+	}
+	// 免打扰时段只延后非紧急的到期提醒
+	if msg.Event == EventReminder && !IsUrgent(msg) && InQuietHours(channel, time.Now()) {
+		return ErrQuietHours
+	}
+
+	if msg.Language == "" {
+		msg.Language = channel.Language
+	}
+	if !IsSupportedLanguage(msg.Language) {
+		msg.Language = DefaultLanguage
+	}
+
+	// 普通用户的渠道只能访问公网地址
+	var role string
+	database.DB.Model(&models.User{}).Select("role").Where("id = ?", channel.UserID).Scan(&role)
+	if TargetsRestricted(role) {
+		ctx = restrictTargets(ctx)
+	}
+
+	if _, ok := n.(ResourceSender); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, SendTimeout)
+		defer cancel()
+	}
+	return n.Send(ctx, msg)
+}
+
+// TestMessage 构造一条测试消息，包含一个 7 天后到期的示例资源
+func TestMessage() Message {
+	expireAt := time.Now().AddDate(0, 0, 7)
+	return Message{
+		Event: EventTest,
+		Resources: []models.ResourceResponse{{
+			Name:          "Tally",
+			GroupName:     "test",
+			ExpireAt:      expireAt.Unix(),
+			CreatedAt:     time.Now().Unix(),
+			RemainingDays: 7,
+		}},
+	}
+}
+
+// DispatchEvent 异步将资源变更事件发送到资源所在工作区所有成员的启用渠道
+func DispatchEvent(event Event, resource models.Resource) {
+	members := database.DB.Model(&models.WorkspaceMember{}).Select("user_id").Where("workspace_id = ?", resource.WorkspaceID)
+
+	var channels []models.NotificationChannel
+	if err := database.DB.Where("user_id IN (?) AND enabled = ?", members, true).Find(&channels).Error; err != nil {
+		log.Printf("Notify: failed to load channels for workspace %d: %v", resource.WorkspaceID, err)
+		return
+	}
+
+	msg := Message{Event: event, Resources: []models.ResourceResponse{resource.ToResponse()}}
+	for i := range channels {
+		go func(channel models.NotificationChannel) {
+			err := Send(context.Background(), &channel, msg)
+			if err != nil && !errors.Is(err, ErrNotSubscribed) {
+				log.Printf("Notify: failed to send %s to channel %d (%s): %v", event, channel.ID, channel.Type, err)
+			}
+		}(channels[i])
+	}
+}
@@ -0,0 +1,392 @@
+package notifier
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/tls"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"html/template"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"net/textproto"
+	"strconv"
+	"strings"
+	"time"
+
+	"tally/models"
+)
+
+// SMTP 连接加密方式
+const (
+	SecurityNone     = "none"     // 明文（仅建议用于本机中继）
+	SecurityStartTLS = "starttls" // 明文连接后升级 TLS，通常为 587 端口
+	SecurityTLS      = "tls"      // 隐式 TLS，通常为 465 端口
+)
+
+// emailConfig 邮件渠道配置
+type emailConfig struct {
+	Host               string   `json:"host"`
+	Port               int      `json:"port"`
+	Security           string   `json:"security"`
+	Username           string   `json:"username"`
+	Password           string   `json:"password"`
+	From               string   `json:"from"`
+	To                 []string `json:"to"`
+	InsecureSkipVerify bool     `json:"insecure_skip_verify"`
+}
+
+type emailNotifier struct {
+	config emailConfig
+}
+
+func init() {
+	Register("email", newEmailNotifier)
+}
+
+func newEmailNotifier(channel *models.NotificationChannel) (Notifier, error) {
+	var cfg emailConfig
+	if err := decodeConfig(channel, &cfg); err != nil {
+		return nil, err
+	}
+
+	if cfg.Host == "" {
+		return nil, errors.New("email: host is required")
+	}
+	if cfg.Security == "" {
+		cfg.Security = SecurityStartTLS
+	}
+	if cfg.Port == 0 {
+		switch cfg.Security {
+		case SecurityTLS:
+			cfg.Port = 465
+		case SecurityStartTLS:
+			cfg.Port = 587
+		default:
+			cfg.Port = 25
+		}
+	}
+	switch cfg.Security {
+	case SecurityNone, SecurityStartTLS, SecurityTLS:
+	default:
+		return nil, fmt.Errorf("email: unsupported security %q", cfg.Security)
+	}
+
+	if cfg.From == "" {
+		cfg.From = cfg.Username
+	}
+	if _, err := mail.ParseAddress(cfg.From); err != nil {
+		return nil, fmt.Errorf("email: invalid from address: %w", err)
+	}
+	if len(cfg.To) == 0 {
+		return nil, errors.New("email: at least one recipient is required")
+	}
+	for _, to := range cfg.To {
+		if _, err := mail.ParseAddress(to); err != nil {
+			return nil, fmt.Errorf("email: invalid recipient %q: %w", to, err)
+		}
+	}
+
+	return &emailNotifier{config: cfg}, nil
+}
+
+func (n *emailNotifier) Send(ctx context.Context, msg Message) error {
+	body, err := n.buildMessage(msg)
+	if err != nil {
+		return err
+	}
+	return n.deliver(ctx, body)
+}
+
+// deliver 建立 SMTP 连接并投递邮件
+func (n *emailNotifier) deliver(ctx context.Context, body []byte) error {
+	cfg := n.config
+	addr := net.JoinHostPort(cfg.Host, strconv.Itoa(cfg.Port))
+	tlsConfig := &tls.Config{ServerName: cfg.Host, InsecureSkipVerify: cfg.InsecureSkipVerify}
+
+	conn, err := dialer.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return fmt.Errorf("email: dial %s: %w", addr, err)
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+	if cfg.Security == SecurityTLS {
+		conn = tls.Client(conn, tlsConfig)
+	}
+
+	client, err := smtp.NewClient(conn, cfg.Host)
+	if err != nil {
+		conn.Close()
+		return fmt.Errorf("email: %w", err)
+	}
+	defer client.Close()
+
+	if cfg.Security == SecurityStartTLS {
+		if ok, _ := client.Extension("STARTTLS"); !ok {
+			return errors.New("email: server does not support STARTTLS")
+		}
+		if err := client.StartTLS(tlsConfig); err != nil {
+			return fmt.Errorf("email: starttls: %w", err)
+		}
+	}
+
+	if cfg.Username != "" {
+		if ok, _ := client.Extension("AUTH"); !ok {
+			return errors.New("email: server does not support AUTH")
+		}
+		if err := client.Auth(smtp.PlainAuth("", cfg.Username, cfg.Password, cfg.Host)); err != nil {
+			return fmt.Errorf("email: auth: %w", err)
+		}
+	}
+
+	from, _ := mail.ParseAddress(cfg.From)
+	if err := client.Mail(from.Address); err != nil {
+		return fmt.Errorf("email: MAIL FROM: %w", err)
+	}
+	for _, to := range cfg.To {
+		addr, _ := mail.ParseAddress(to)
+		if err := client.Rcpt(addr.Address); err != nil {
+			return fmt.Errorf("email: RCPT TO %s: %w", addr.Address, err)
+		}
+	}
+
+	w, err := client.Data()
+	if err != nil {
+		return fmt.Errorf("email: DATA: %w", err)
+	}
+	if _, err := w.Write(body); err != nil {
+		return fmt.Errorf("email: write body: %w", err)
+	}
+	if err := w.Close(); err != nil {
+		return fmt.Errorf("email: %w", err)
+	}
+	return client.Quit()
+}
+
+// buildMessage 构造 multipart/alternative 邮件（纯文本 + HTML）
+func (n *emailNotifier) buildMessage(msg Message) ([]byte, error) {
+	htmlBody, err := HTML(msg)
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	writer := multipart.NewWriter(&buf)
+
+	header := func(key, value string) {
+		fmt.Fprintf(&buf, "%s: %s\r\n", key, value)
+	}
+	header("From", formatAddress(n.config.From))
+	to := make([]string, len(n.config.To))
+	for i, addr := range n.config.To {
+		to[i] = formatAddress(addr)
+	}
+	header("To", strings.Join(to, ", "))
+	header("Subject", mime.BEncoding.Encode("UTF-8", Title(msg)))
+	header("Date", time.Now().Format(time.RFC1123Z))
+	header("Message-ID", messageID(n.config.Host))
+	header("MIME-Version", "1.0")
+	header("Content-Type", "multipart/alternative; boundary="+writer.Boundary())
+	buf.WriteString("\r\n")
+
+	parts := []struct {
+		contentType string
+		content     string
+	}{
+		{"text/plain; charset=UTF-8", PlainText(msg)},
+		{"text/html; charset=UTF-8", htmlBody},
+	}
+	for _, p := range parts {
+		part, err := writer.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {p.contentType},
+			"Content-Transfer-Encoding": {"quoted-printable"},
+		})
+		if err != nil {
+			return nil, err
+		}
+		qp := quotedprintable.NewWriter(part)
+		if _, err := qp.Write([]byte(p.content)); err != nil {
+			return nil, err
+		}
+		if err := qp.Close(); err != nil {
+			return nil, err
+		}
+	}
+	if err := writer.Close(); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// formatAddress 规范化邮件地址，非 ASCII 显示名按 RFC 2047 编码
+func formatAddress(addr string) string {
+	parsed, err := mail.ParseAddress(addr)
+	if err != nil {
+		return addr
+	}
+	return parsed.String()
+}
+
+// messageID 生成邮件 Message-ID
+func messageID(host string) string {
+	b := make([]byte, 12)
+	rand.Read(b)
+	return fmt.Sprintf("<%d.%s@%s>", time.Now().UnixNano(), hex.EncodeToString(b), host)
+}
+
+var htmlTemplate = template.Must(template.New("email").Parse(`<!DOCTYPE html>
+<html>
+<body style="font-family: -apple-system, BlinkMacSystemFont, 'Segoe UI', sans-serif; color: #111827;">
+  <h2 style="color: #4f46e5;">{{.Title}}</h2>
+  <p>{{.Intro}}</p>
+  <table cellpadding="8" cellspacing="0" style="border-collapse: collapse; border: 1px solid #e5e7eb;">
+    <thead>
+      <tr style="background: #f9fafb; text-align: left;">
+        <th>{{.Labels.Name}}</th><th>{{.Labels.Group}}</th><th>{{.Labels.ExpireDate}}</th><th>{{.Labels.Remaining}}</th>
+      </tr>
+    </thead>
+    <tbody>
+      {{range .Rows}}
+      <tr style="border-top: 1px solid #e5e7eb;">
+        <td>{{.Name}}</td><td>{{.Group}}</td><td>{{.ExpireDate}}</td>
+        <td style="color: {{.Color}}; font-weight: 600;">{{.Remaining}}</td>
+      </tr>
+      {{end}}
+    </tbody>
+  </table>
+  <p style="color: #6b7280; font-size: 12px;">{{.Footer}}</p>
+</body>
+</html>
+`))
+
+var digestHTMLTemplate = template.Must(template.New("digest").Parse(`<!DOCTYPE html>
+<html>
+<body style="font-family: -apple-system, BlinkMacSystemFont, 'Segoe UI', sans-serif; color: #111827;">
+  <h2 style="color: #4f46e5;">{{.Title}}</h2>
+  <p>{{.Intro}}</p>
+  {{range .Sections}}
+  <h3 style="margin-bottom: 4px;">{{.Title}}</h3>
+  {{range .Groups}}
+  <p style="margin: 8px 0 4px; font-weight: 600; color: #4b5563;">{{.Name}}</p>
+  <table cellpadding="6" cellspacing="0" style="border-collapse: collapse; border: 1px solid #e5e7eb;">
+    {{range .Rows}}
+    <tr style="border-top: 1px solid #e5e7eb;">
+      <td>{{.Name}}</td><td>{{.ExpireDate}}</td>
+      <td style="color: {{.Color}}; font-weight: 600;">{{.Remaining}}</td>
+    </tr>
+    {{end}}
+  </table>
+  {{end}}
+  {{end}}
+  <p style="color: #6b7280; font-size: 12px;">{{.Footer}}</p>
+</body>
+</html>
+`))
+
+// digestHTML HTML 格式的摘要正文
+func digestHTML(msg Message) (string, error) {
+	type row struct {
+		Name, ExpireDate, Remaining, Color string
+	}
+	type group struct {
+		Name string
+		Rows []row
+	}
+	type section struct {
+		Title  string
+		Groups []group
+	}
+	data := struct {
+		Title, Intro, Footer string
+		Sections             []section
+	}{
+		Title:  Title(msg),
+		Intro:  intro(msg),
+		Footer: t(msg.Language, "footer"),
+	}
+
+	for _, s := range msg.Digest.Sections {
+		sec := section{Title: sectionTitle(msg.Language, s.Key)}
+		for _, g := range s.Groups {
+			grp := group{Name: digestGroupName(msg.Language, g.GroupName)}
+			for _, r := range g.Resources {
+				grp.Rows = append(grp.Rows, row{
+					Name:       r.Name,
+					ExpireDate: dateText(r),
+					Remaining:  remainingText(msg.Language, r.RemainingDays),
+					Color:      remainingColor(r.RemainingDays),
+				})
+			}
+			sec.Groups = append(sec.Groups, grp)
+		}
+		data.Sections = append(data.Sections, sec)
+	}
+
+	var buf bytes.Buffer
+	if err := digestHTMLTemplate.Execute(&buf, data); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+// HTML HTML 格式的消息正文
+func HTML(msg Message) (string, error) {
+	if msg.Digest != nil {
+		return digestHTML(msg)
+	}
+
+	type row struct {
+		Name, Group, ExpireDate, Remaining, Color string
+	}
+	data := struct {
+		Title, Intro, Footer string
+		Labels               struct{ Name, Group, ExpireDate, Remaining string }
+		Rows                 []row
+	}{
+		Title:  Title(msg),
+		Intro:  intro(msg),
+		Footer: t(msg.Language, "footer"),
+	}
+	data.Labels.Name = t(msg.Language, "resourceName")
+	data.Labels.Group = t(msg.Language, "group")
+	data.Labels.ExpireDate = t(msg.Language, "expireDate")
+	data.Labels.Remaining = t(msg.Language, "remainingDays")
+
+	for _, r := range sortedResources(msg.Resources) {
+		data.Rows = append(data.Rows, row{
+			Name:       r.Name,
+			Group:      groupText(msg.Language, r),
+			ExpireDate: dateText(r),
+			Remaining:  remainingText(msg.Language, r.RemainingDays),
+			Color:      remainingColor(r.RemainingDays),
+		})
+	}
+
+	var buf bytes.Buffer
+	if err := htmlTemplate.Execute(&buf, data); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+// remainingColor 剩余天数的高亮颜色，与前端列表的配色一致
+func remainingColor(days int) string {
+	switch {
+	case days <= 0:
+		return "#dc2626"
+	case days <= 7:
+		return "#ea580c"
+	case days <= 30:
+		return "#ca8a04"
+	default:
+		return "#16a34a"
+	}
+}
@@ -0,0 +1,300 @@
+package notifier
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/base64"
+	"encoding/json"
+	"mime"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/mail"
+	"net/textproto"
+	"strings"
+	"testing"
+	"time"
+
+	"tally/models"
+)
+
+// smtpMessage 本地 SMTP 替身收到的一封邮件
+type smtpMessage struct {
+	auth string // AUTH PLAIN 解码后的内容：\x00username\x00password
+	from string
+	to   []string
+	data string
+	tls  bool
+}
+
+// fakeSMTP 本地 SMTP 替身，只实现投递邮件所需的命令
+type fakeSMTP struct {
+	ln       net.Listener
+	startTLS *tls.Config // 非 nil 时支持 STARTTLS
+	received chan smtpMessage
+}
+
+// testCertificate 借用 httptest 的自签名证书
+func testCertificate(t *testing.T) *tls.Config {
+	t.Helper()
+	ts := httptest.NewTLSServer(http.NotFoundHandler())
+	t.Cleanup(ts.Close)
+	return &tls.Config{Certificates: ts.TLS.Certificates}
+}
+
+// startFakeSMTP 启动 SMTP 替身，implicitTLS 为 true 时监听 TLS，startTLS 为 true 时支持 STARTTLS
+func startFakeSMTP(t *testing.T, implicitTLS, startTLS bool) *fakeSMTP {
+	t.Helper()
+
+	var ln net.Listener
+	var err error
+	if implicitTLS {
+		ln, err = tls.Listen("tcp", "127.0.0.1:0", testCertificate(t))
+	} else {
+		ln, err = net.Listen("tcp", "127.0.0.1:0")
+	}
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	t.Cleanup(func() { ln.Close() })
+
+	s := &fakeSMTP{ln: ln, received: make(chan smtpMessage, 1)}
+	if startTLS {
+		s.startTLS = testCertificate(t)
+	}
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go s.serve(conn, implicitTLS)
+		}
+	}()
+	return s
+}
+
+func (s *fakeSMTP) port() int {
+	return s.ln.Addr().(*net.TCPAddr).Port
+}
+
+func (s *fakeSMTP) serve(conn net.Conn, secure bool) {
+	defer func() { conn.Close() }()
+	conn.SetDeadline(time.Now().Add(10 * time.Second))
+
+	tp := textproto.NewConn(conn)
+	tp.PrintfLine("220 localhost ESMTP")
+	msg := smtpMessage{tls: secure}
+	for {
+		line, err := tp.ReadLine()
+		if err != nil {
+			return
+		}
+		cmd, arg, _ := strings.Cut(line, " ")
+		switch strings.ToUpper(cmd) {
+		case "EHLO", "HELO":
+			tp.PrintfLine("250-localhost")
+			if s.startTLS != nil && !msg.tls {
+				tp.PrintfLine("250-STARTTLS")
+			}
+			tp.PrintfLine("250 AUTH PLAIN")
+		case "STARTTLS":
+			tp.PrintfLine("220 ready to start TLS")
+			tlsConn := tls.Server(conn, s.startTLS)
+			if err := tlsConn.Handshake(); err != nil {
+				return
+			}
+			conn = tlsConn
+			tp = textproto.NewConn(conn)
+			msg.tls = true
+		case "AUTH":
+			_, initial, _ := strings.Cut(arg, " ")
+			decoded, _ := base64.StdEncoding.DecodeString(initial)
+			msg.auth = string(decoded)
+			tp.PrintfLine("235 authenticated")
+		case "MAIL":
+			msg.from = strings.Trim(strings.TrimPrefix(arg, "FROM:"), "<>")
+			tp.PrintfLine("250 ok")
+		case "RCPT":
+			msg.to = append(msg.to, strings.Trim(strings.TrimPrefix(arg, "TO:"), "<>"))
+			tp.PrintfLine("250 ok")
+		case "DATA":
+			tp.PrintfLine("354 end with <CRLF>.<CRLF>")
+			data, err := tp.ReadDotBytes()
+			if err != nil {
+				return
+			}
+			msg.data = string(data)
+			tp.PrintfLine("250 queued")
+			s.received <- msg
+		case "QUIT":
+			tp.PrintfLine("221 bye")
+			return
+		default:
+			tp.PrintfLine("502 command not implemented")
+		}
+	}
+}
+
+func (s *fakeSMTP) wait(t *testing.T) smtpMessage {
+	t.Helper()
+	select {
+	case msg := <-s.received:
+		return msg
+	case <-time.After(5 * time.Second):
+		t.Fatal("no mail received")
+		return smtpMessage{}
+	}
+}
+
+func emailChannel(t *testing.T, cfg map[string]interface{}) Notifier {
+	t.Helper()
+	b, _ := json.Marshal(cfg)
+	n, err := New(&models.NotificationChannel{Type: "email", Config: string(b)})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	return n
+}
+
+func reminderMessage() Message {
+	return Message{
+		Event:    EventReminder,
+		Language: "en",
+		Resources: []models.ResourceResponse{
+			{ID: 2, Name: "example.com", GroupName: "domains", ExpireAt: time.Now().AddDate(0, 0, 3).Unix(), RemainingDays: 3},
+			{ID: 1, Name: "vps", GroupName: "servers", ExpireAt: time.Now().AddDate(0, 0, -1).Unix(), RemainingDays: -1},
+		},
+	}
+}
+
+func TestEmailSendPlain(t *testing.T) {
+	server := startFakeSMTP(t, false, false)
+	n := emailChannel(t, map[string]interface{}{
+		"host":     "127.0.0.1",
+		"port":     server.port(),
+		"security": SecurityNone,
+		"username": "tally",
+		"password": "secret",
+		"from":     "Tally <tally@example.com>",
+		"to":       []string{"ops@example.com", "Admin <admin@example.com>"},
+	})
+
+	msg := reminderMessage()
+	if err := n.Send(context.Background(), msg); err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+	got := server.wait(t)
+
+	if got.auth != "\x00tally\x00secret" {
+		t.Errorf("auth = %q", got.auth)
+	}
+	if got.from != "tally@example.com" {
+		t.Errorf("from = %q", got.from)
+	}
+	if strings.Join(got.to, ",") != "ops@example.com,admin@example.com" {
+		t.Errorf("to = %v", got.to)
+	}
+
+	parsed, err := mail.ReadMessage(strings.NewReader(got.data))
+	if err != nil {
+		t.Fatalf("parse message: %v", err)
+	}
+	subject, err := new(mime.WordDecoder).DecodeHeader(parsed.Header.Get("Subject"))
+	if err != nil || subject != Title(msg) {
+		t.Errorf("subject = %q, want %q", subject, Title(msg))
+	}
+	if ct := parsed.Header.Get("Content-Type"); !strings.HasPrefix(ct, "multipart/alternative") {
+		t.Errorf("content type = %q", ct)
+	}
+	for _, want := range []string{"text/plain; charset=UTF-8", "text/html; charset=UTF-8", "example.com", "vps"} {
+		if !strings.Contains(got.data, want) {
+			t.Errorf("message does not contain %q", want)
+		}
+	}
+}
+
+func TestEmailSendStartTLS(t *testing.T) {
+	server := startFakeSMTP(t, false, true)
+	n := emailChannel(t, map[string]interface{}{
+		"host":                 "127.0.0.1",
+		"port":                 server.port(),
+		"security":             SecurityStartTLS,
+		"username":             "tally@example.com",
+		"password":             "secret",
+		"to":                   []string{"ops@example.com"},
+		"insecure_skip_verify": true,
+	})
+
+	if err := n.Send(context.Background(), TestMessage()); err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+	got := server.wait(t)
+	if !got.tls {
+		t.Error("mail was not sent over TLS")
+	}
+	if got.from != "tally@example.com" {
+		t.Errorf("from defaults to username, got %q", got.from)
+	}
+}
+
+func TestEmailSendImplicitTLS(t *testing.T) {
+	server := startFakeSMTP(t, true, false)
+	n := emailChannel(t, map[string]interface{}{
+		"host":                 "127.0.0.1",
+		"port":                 server.port(),
+		"security":             SecurityTLS,
+		"from":                 "tally@example.com",
+		"to":                   []string{"ops@example.com"},
+		"insecure_skip_verify": true,
+	})
+
+	if err := n.Send(context.Background(), TestMessage()); err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+	if got := server.wait(t); got.auth != "" {
+		t.Errorf("unexpected AUTH without username: %q", got.auth)
+	}
+}
+
+func TestEmailStartTLSUnsupported(t *testing.T) {
+	server := startFakeSMTP(t, false, false)
+	n := emailChannel(t, map[string]interface{}{
+		"host":     "127.0.0.1",
+		"port":     server.port(),
+		"security": SecurityStartTLS,
+		"from":     "tally@example.com",
+		"to":       []string{"ops@example.com"},
+	})
+
+	err := n.Send(context.Background(), TestMessage())
+	if err == nil || !strings.Contains(err.Error(), "STARTTLS") {
+		t.Fatalf("err = %v, want STARTTLS error", err)
+	}
+}
+
+func TestEmailConfigValidation(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  string
+	}{
+		{"missing host", `{"from":"a@example.com","to":["b@example.com"]}`},
+		{"bad security", `{"host":"smtp.example.com","security":"ssl","from":"a@example.com","to":["b@example.com"]}`},
+		{"bad from", `{"host":"smtp.example.com","from":"not an address","to":["b@example.com"]}`},
+		{"no recipients", `{"host":"smtp.example.com","from":"a@example.com"}`},
+		{"bad recipient", `{"host":"smtp.example.com","from":"a@example.com","to":["nope"]}`},
+	}
+	for _, tt := range tests {
+		if _, err := New(&models.NotificationChannel{Type: "email", Config: tt.cfg}); err == nil {
+			t.Errorf("%s: expected error", tt.name)
+		}
+	}
+
+	n, err := New(&models.NotificationChannel{Type: "email", Config: `{"host":"smtp.example.com","security":"tls","from":"a@example.com","to":["b@example.com"]}`})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	if port := n.(*emailNotifier).config.Port; port != 465 {
+		t.Errorf("default tls port = %d", port)
+	}
+}
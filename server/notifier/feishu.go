@@ -0,0 +1,97 @@
+package notifier
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	"tally/models"
+)
+
+// feishuConfig 飞书 / Lark 群机器人配置
+type feishuConfig struct {
+	URL    string `json:"url"`    // 机器人 Webhook 地址，Lark 国际版同样适用
+	Secret string `json:"secret"` // 签名校验密钥，为空表示未开启
+}
+
+type feishuNotifier struct {
+	config feishuConfig
+}
+
+func init() {
+	Register("feishu", func(channel *models.NotificationChannel) (Notifier, error) {
+		var cfg feishuConfig
+		if err := decodeConfig(channel, &cfg); err != nil {
+			return nil, err
+		}
+		if err := validateURL(cfg.URL); err != nil {
+			return nil, fmt.Errorf("feishu: %w", err)
+		}
+		return &feishuNotifier{config: cfg}, nil
+	})
+}
+
+func (n *feishuNotifier) Send(ctx context.Context, msg Message) error {
+	// 卡片标题颜色随最紧急资源变化
+	template := "blue"
+	if days, ok := minRemainingDays(msg); ok && msg.Event != EventTest {
+		switch {
+		case days <= 0:
+			template = "red"
+		case days <= 7:
+			template = "orange"
+		default:
+			template = "yellow"
+		}
+	}
+
+	payload := map[string]interface{}{
+		"msg_type": "interactive",
+		"card": map[string]interface{}{
+			"config": map[string]bool{"wide_screen_mode": true},
+			"header": map[string]interface{}{
+				"title":    map[string]string{"tag": "plain_text", "content": Title(msg)},
+				"template": template,
+			},
+			"elements": []interface{}{
+				map[string]interface{}{
+					"tag":  "div",
+					"text": map[string]string{"tag": "lark_md", "content": Markdown(msg)},
+				},
+			},
+		},
+	}
+	if n.config.Secret != "" {
+		timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+		payload["timestamp"] = timestamp
+		payload["sign"] = feishuSign(n.config.Secret, timestamp)
+	}
+
+	body, err := postJSON(ctx, n.config.URL, payload, nil)
+	if err != nil {
+		return fmt.Errorf("feishu: %w", err)
+	}
+
+	var result struct {
+		Code int    `json:"code"`
+		Msg  string `json:"msg"`
+	}
+	if err := json.Unmarshal(body, &result); err != nil {
+		return fmt.Errorf("feishu: invalid response: %w", err)
+	}
+	if result.Code != 0 {
+		return fmt.Errorf("feishu: code %d: %s", result.Code, result.Msg)
+	}
+	return nil
+}
+
+// feishuSign 飞书签名：以 timestamp + "\n" + secret 为密钥对空串做 HMAC-SHA256，再 Base64
+func feishuSign(secret, timestamp string) string {
+	mac := hmac.New(sha256.New, []byte(timestamp+"\n"+secret))
+	return base64.StdEncoding.EncodeToString(mac.Sum(nil))
+}
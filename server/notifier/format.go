@@ -0,0 +1,148 @@
+package notifier
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"tally/models"
+)
+
+// Title 消息标题
+func Title(msg Message) string {
+	switch {
+	case msg.Event == EventTest:
+		return t(msg.Language, "testTitle")
+	case msg.Digest != nil:
+		return t(msg.Language, "digestTitle_"+msg.Digest.Frequency, "count", msg.Digest.Count())
+	case msg.Event == EventEscalation:
+		return t(msg.Language, "escalationTitle",
+			"step", msg.EscalationStep, "steps", msg.EscalationSteps, "count", len(msg.Resources))
+	}
+	return t(msg.Language, "reminderTitle", "count", len(msg.Resources))
+}
+
+// intro 消息正文开头的说明
+func intro(msg Message) string {
+	switch {
+	case msg.Event == EventTest:
+		return t(msg.Language, "testIntro")
+	case msg.Digest != nil:
+		return t(msg.Language, "digestIntro")
+	case msg.Event == EventEscalation:
+		return t(msg.Language, "escalationIntro")
+	}
+	return t(msg.Language, "reminderIntro")
+}
+
+// remainingText 剩余天数的本地化描述
+func remainingText(lang string, days int) string {
+	switch {
+	case days < 0:
+		return t(lang, "expiredDays", "days", -days)
+	case days == 0:
+		return t(lang, "expiresToday")
+	default:
+		return t(lang, "daysLeft", "days", days)
+	}
+}
+
+// groupText 分组名称，空分组显示为“未分组”
+func groupText(lang string, r models.ResourceResponse) string {
+	if r.GroupName == "" {
+		return t(lang, "noGroup")
+	}
+	return r.GroupName
+}
+
+// dateText 到期日期，按服务器本地时区格式化
+func dateText(r models.ResourceResponse) string {
+	return time.Unix(r.ExpireAt, 0).Format("2006-01-02")
+}
+
+// PlainText 纯文本格式的消息正文
+func PlainText(msg Message) string {
+	if msg.Digest != nil {
+		return digestPlainText(msg)
+	}
+
+	var b strings.Builder
+	b.WriteString(intro(msg))
+	b.WriteString("\n\n")
+	for _, r := range sortedResources(msg.Resources) {
+		fmt.Fprintf(&b, "- %s [%s] %s (%s)\n",
+			r.Name, groupText(msg.Language, r), dateText(r), remainingText(msg.Language, r.RemainingDays))
+	}
+	return b.String()
+}
+
+// Markdown Markdown 格式的消息正文（不含标题），资源按到期时间升序排列
+func Markdown(msg Message) string {
+	if msg.Digest != nil {
+		return digestMarkdown(msg)
+	}
+
+	var b strings.Builder
+	b.WriteString(intro(msg))
+	b.WriteString("\n\n")
+	for _, r := range sortedResources(msg.Resources) {
+		fmt.Fprintf(&b, "- **%s** [%s] %s (%s)\n",
+			r.Name, groupText(msg.Language, r), dateText(r), remainingText(msg.Language, r.RemainingDays))
+	}
+	return b.String()
+}
+
+// sortedResources 返回按到期时间升序排列的副本，与资源列表接口的排序一致
+func sortedResources(resources []models.ResourceResponse) []models.ResourceResponse {
+	sorted := append([]models.ResourceResponse(nil), resources...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].ExpireAt < sorted[j].ExpireAt
+	})
+	return sorted
+}
+
+// minRemainingDays 消息中最紧急资源的剩余天数，无资源时返回 false
+func minRemainingDays(msg Message) (int, bool) {
+	if len(msg.Resources) == 0 {
+		return 0, false
+	}
+	min := msg.Resources[0].RemainingDays
+	for _, r := range msg.Resources[1:] {
+		if r.RemainingDays < min {
+			min = r.RemainingDays
+		}
+	}
+	return min, true
+}
+
+// Priority 通知紧急程度，各推送渠道按自身的级别映射
+type Priority int
+
+const (
+	PriorityLow     Priority = iota // 剩余 7 天以上
+	PriorityDefault                 // 剩余 4~7 天，或测试消息
+	PriorityHigh                    // 剩余 1~3 天
+	PriorityUrgent                  // 今天到期或已过期，或升级通知
+)
+
+// priorityOf 根据最紧急资源的剩余天数计算消息优先级
+func priorityOf(msg Message) Priority {
+	if msg.Event == EventEscalation {
+		return PriorityUrgent
+	}
+	days, ok := minRemainingDays(msg)
+	if !ok || msg.Event == EventTest {
+		return PriorityDefault
+	}
+	switch {
+	case days <= 0:
+		return PriorityUrgent
+	case days <= 3:
+		return PriorityHigh
+	case days <= 7:
+		return PriorityDefault
+	default:
+		return PriorityLow
+	}
+}
@@ -0,0 +1,62 @@
+package notifier
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"tally/models"
+)
+
+// gotifyConfig Gotify 推送配置
+type gotifyConfig struct {
+	Server string `json:"server"`
+	Token  string `json:"token"` // 应用 Token
+}
+
+type gotifyNotifier struct {
+	config gotifyConfig
+}
+
+// gotifyPriorities Gotify 优先级 0~10，客户端通常在 8 以上发出强提醒
+var gotifyPriorities = map[Priority]int{
+	PriorityLow:     2,
+	PriorityDefault: 5,
+	PriorityHigh:    7,
+	PriorityUrgent:  10,
+}
+
+func init() {
+	Register("gotify", func(channel *models.NotificationChannel) (Notifier, error) {
+		var cfg gotifyConfig
+		if err := decodeConfig(channel, &cfg); err != nil {
+			return nil, err
+		}
+		cfg.Server = strings.TrimRight(cfg.Server, "/")
+		if err := validateURL(cfg.Server); err != nil {
+			return nil, fmt.Errorf("gotify: server %w", err)
+		}
+		if cfg.Token == "" {
+			return nil, errors.New("gotify: token is required")
+		}
+		return &gotifyNotifier{config: cfg}, nil
+	})
+}
+
+func (n *gotifyNotifier) Send(ctx context.Context, msg Message) error {
+	payload := map[string]interface{}{
+		"title":    Title(msg),
+		"message":  Markdown(msg),
+		"priority": gotifyPriorities[priorityOf(msg)],
+		"extras": map[string]interface{}{
+			"client::display": map[string]string{"contentType": "text/markdown"},
+		},
+	}
+
+	headers := map[string]string{"X-Gotify-Key": n.config.Token}
+	if _, err := postJSON(ctx, n.config.Server+"/message", payload, headers); err != nil {
+		return fmt.Errorf("gotify: %w", err)
+	}
+	return nil
+}
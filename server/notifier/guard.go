@@ -0,0 +1,104 @@
+package notifier
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net"
+	"net/http"
+	"net/url"
+	"syscall"
+	"time"
+
+	"tally/config"
+	"tally/models"
+)
+
+// ErrPrivateTarget 普通用户的渠道不能访问内网、回环和链路本地地址
+var ErrPrivateTarget = errors.New("private, loopback and link-local addresses are only allowed for administrators")
+
+type restrictKey struct{}
+
+// restrictTargets 标记本次发送只能连接公网地址，在建立连接（DNS 解析之后）时检查，避免 DNS 重绑定绕过
+func restrictTargets(ctx context.Context) context.Context {
+	return context.WithValue(ctx, restrictKey{}, true)
+}
+
+// TargetsRestricted 判断该角色的用户创建的渠道是否只能访问公网地址，NOTIFY_ALLOW_PRIVATE_TARGETS=true 时不限制
+func TargetsRestricted(role string) bool {
+	return role != models.RoleAdmin && !config.GetNotifyAllowPrivateTargets()
+}
+
+// publicIP 判断地址是否允许普通用户的渠道访问
+func publicIP(ip net.IP) bool {
+	return !(ip.IsLoopback() || ip.IsPrivate() || ip.IsUnspecified() ||
+		ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() || ip.IsInterfaceLocalMulticast())
+}
+
+// dialer 所有渠道共用的拨号器，受限的发送只能连接公网地址
+var dialer = &net.Dialer{
+	Timeout:   10 * time.Second,
+	KeepAlive: 30 * time.Second,
+	ControlContext: func(ctx context.Context, network, address string, _ syscall.RawConn) error {
+		if restricted, _ := ctx.Value(restrictKey{}).(bool); !restricted {
+			return nil
+		}
+		host, _, err := net.SplitHostPort(address)
+		if err != nil {
+			return err
+		}
+		if ip := net.ParseIP(host); ip == nil || !publicIP(ip) {
+			return ErrPrivateTarget
+		}
+		return nil
+	},
+}
+
+// transport 使用 dialer 的 HTTP 传输层
+var transport = func() *http.Transport {
+	t := http.DefaultTransport.(*http.Transport).Clone()
+	t.DialContext = dialer.DialContext
+	return t
+}()
+
+// CheckTargets 解析渠道配置中的目标地址（url、server、host 字段），任一地址不是公网地址时返回 ErrPrivateTarget
+// 创建和修改渠道时提前提示，发送时仍会在连接时检查
+func CheckTargets(ctx context.Context, channel *models.NotificationChannel) error {
+	var fields map[string]interface{}
+	if json.Unmarshal([]byte(channel.Config), &fields) != nil {
+		return nil
+	}
+
+	for _, key := range []string{"url", "server", "host"} {
+		host, _ := fields[key].(string)
+		if host == "" {
+			continue
+		}
+		if key != "host" {
+			u, err := url.Parse(host)
+			if err != nil {
+				continue
+			}
+			host = u.Hostname()
+		}
+
+		ips := []net.IP{net.ParseIP(host)}
+		if ips[0] == nil {
+			// 解析失败时交给发送时的连接检查
+			addrs, err := net.DefaultResolver.LookupIPAddr(ctx, host)
+			if err != nil {
+				continue
+			}
+			ips = ips[:0]
+			for _, addr := range addrs {
+				ips = append(ips, addr.IP)
+			}
+		}
+		for _, ip := range ips {
+			if !publicIP(ip) {
+				return ErrPrivateTarget
+			}
+		}
+	}
+	return nil
+}
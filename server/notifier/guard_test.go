@@ -0,0 +1,68 @@
+package notifier
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+
+	"tally/models"
+)
+
+func TestRestrictedSendRejectsLoopback(t *testing.T) {
+	ts, requests := startPushServer(t, http.StatusOK, `{}`)
+	n := newChannel(t, "webhook", map[string]interface{}{"url": ts.URL, "max_retries": 0})
+	msg := Message{Event: EventTest, Resources: []models.ResourceResponse{{ID: 1}}}
+
+	err := n.Send(restrictTargets(context.Background()), msg)
+	if !errors.Is(err, ErrPrivateTarget) {
+		t.Fatalf("restricted send: err = %v, want ErrPrivateTarget", err)
+	}
+	select {
+	case <-requests:
+		t.Fatal("restricted send reached the loopback server")
+	default:
+	}
+
+	if err := n.Send(context.Background(), msg); err != nil {
+		t.Fatalf("unrestricted send: %v", err)
+	}
+	<-requests
+}
+
+func TestCheckTargets(t *testing.T) {
+	tests := []struct {
+		channelType string
+		config      string
+		private     bool
+	}{
+		{"webhook", `{"url":"http://127.0.0.1:8080/hook"}`, true},
+		{"webhook", `{"url":"http://localhost/hook"}`, true},
+		{"gotify", `{"server":"http://192.168.1.10","token":"t"}`, true},
+		{"ntfy", `{"server":"http://[::1]:8080","topic":"t"}`, true},
+		{"webhook", `{"url":"http://169.254.169.254/latest/meta-data/"}`, true},
+		{"email", `{"host":"10.0.0.5","from":"a@example.com","to":["b@example.com"]}`, true},
+		{"webhook", `{"url":"https://93.184.216.34/hook"}`, false},
+		{"ntfy", `{"topic":"t"}`, false},
+	}
+	for _, tt := range tests {
+		err := CheckTargets(context.Background(), &models.NotificationChannel{Type: tt.channelType, Config: tt.config})
+		if got := errors.Is(err, ErrPrivateTarget); got != tt.private {
+			t.Errorf("%s %s: err = %v, want private = %v", tt.channelType, tt.config, err, tt.private)
+		}
+	}
+}
+
+func TestPublicMessageHidesResponseBody(t *testing.T) {
+	ts, _ := startPushServer(t, http.StatusInternalServerError, "internal secret")
+	n := newChannel(t, "gotify", map[string]interface{}{"server": ts.URL, "token": "t"})
+
+	err := n.Send(context.Background(), TestMessage())
+	if err == nil || !strings.Contains(err.Error(), "internal secret") {
+		t.Fatalf("err = %v, want response body in full error", err)
+	}
+	if msg := PublicMessage(err); msg != "gotify: unexpected status 500" {
+		t.Errorf("PublicMessage = %q", msg)
+	}
+}
@@ -0,0 +1,71 @@
+package notifier
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// httpClient 各机器人渠道共用的 HTTP 客户端
+var httpClient = &http.Client{Timeout: 15 * time.Second, Transport: transport}
+
+// postJSON 发送 JSON 请求，非 2xx 响应视为失败，返回响应体
+func postJSON(ctx context.Context, url string, payload interface{}, headers map[string]string) ([]byte, error) {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json; charset=utf-8")
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	respBody, _ := io.ReadAll(io.LimitReader(resp.Body, 64*1024))
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return respBody, &StatusError{StatusCode: resp.StatusCode, Body: truncate(string(respBody), 256)}
+	}
+	return respBody, nil
+}
+
+// StatusError 对方返回非 2xx 状态码，Body 为截断后的响应内容
+type StatusError struct {
+	StatusCode int
+	Body       string
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("unexpected status %d: %s", e.StatusCode, e.Body)
+}
+
+// PublicMessage 返回去掉对方响应内容的错误信息，用于展示给普通用户
+func PublicMessage(err error) string {
+	msg := err.Error()
+	var status *StatusError
+	if errors.As(err, &status) {
+		msg = strings.Replace(msg, status.Error(), fmt.Sprintf("unexpected status %d", status.StatusCode), 1)
+	}
+	return msg
+}
+
+// basicAuth 构造 HTTP Basic 认证头
+func basicAuth(username, password string) string {
+	return "Basic " + base64.StdEncoding.EncodeToString([]byte(username+":"+password))
+}
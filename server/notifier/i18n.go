@@ -0,0 +1,94 @@
+package notifier
+
+import (
+	"strconv"
+	"strings"
+)
+
+// DefaultLanguage 默认消息语言，与前端默认语言一致
+const DefaultLanguage = "zh"
+
+// translations 通知消息文案，键名与占位符风格与前端 i18n 保持一致
+var translations = map[string]map[string]string{
+	"zh": {
+		"reminderTitle": "Tally 到期提醒：{count} 项资源即将到期",
+		"testTitle":     "Tally 测试通知",
+		"reminderIntro": "以下资源即将到期或已过期：",
+		"testIntro":     "这是一条测试通知，收到说明渠道配置正确。",
+		"resourceName":  "资源名称",
+		"group":         "分组",
+		"expireDate":    "到期日期",
+		"remainingDays": "剩余天数",
+		"noGroup":       "未分组",
+		"expiredDays":   "已过期 {days} 天",
+		"expiresToday":  "今天到期",
+		"daysLeft":      "剩 {days} 天",
+		"footer":        "此邮件由 Tally 自动发送。",
+
+		"digestTitle_daily":  "Tally 每日到期摘要：{count} 项",
+		"digestTitle_weekly": "Tally 每周到期摘要：{count} 项",
+		"digestIntro":        "以下是近期需要关注的资源：",
+		"section_expired":    "已过期",
+		"section_this_week":  "本周到期（7 天内）",
+		"section_this_month": "本月到期（30 天内）",
+
+		"escalationTitle": "Tally 升级提醒（第 {step}/{steps} 级）：{count} 项资源未确认",
+		"escalationIntro": "以下资源的到期提醒尚未被确认，已按升级策略通知你：",
+	},
+	"en": {
+		"reminderTitle": "Tally reminder: {count} resource(s) expiring soon",
+		"testTitle":     "Tally test notification",
+		"reminderIntro": "The following resources are expiring soon or have expired:",
+		"testIntro":     "This is a test notification. If you received it, the channel is configured correctly.",
+		"resourceName":  "Resource",
+		"group":         "Group",
+		"expireDate":    "Expire Date",
+		"remainingDays": "Remaining",
+		"noGroup":       "Ungrouped",
+		"expiredDays":   "Expired {days} day(s) ago",
+		"expiresToday":  "Expires today",
+		"daysLeft":      "{days} day(s) left",
+		"footer":        "This message was sent automatically by Tally.",
+
+		"digestTitle_daily":  "Tally daily digest: {count} resource(s)",
+		"digestTitle_weekly": "Tally weekly digest: {count} resource(s)",
+		"digestIntro":        "Here are the resources that need your attention:",
+		"section_expired":    "Expired",
+		"section_this_week":  "This week (within 7 days)",
+		"section_this_month": "This month (within 30 days)",
+
+		"escalationTitle": "Tally escalation (level {step}/{steps}): {count} unacknowledged resource(s)",
+		"escalationIntro": "Reminders for the following resources have not been acknowledged and were escalated to you:",
+	},
+}
+
+// IsSupportedLanguage 判断是否支持该语言
+func IsSupportedLanguage(lang string) bool {
+	_, ok := translations[lang]
+	return ok
+}
+
+// t 获取指定语言的文案，params 按 key、value 成对替换 {key} 占位符
+func t(lang, key string, params ...interface{}) string {
+	messages, ok := translations[lang]
+	if !ok {
+		messages = translations[DefaultLanguage]
+	}
+	text, ok := messages[key]
+	if !ok {
+		return key
+	}
+
+	for i := 0; i+1 < len(params); i += 2 {
+		name, _ := params[i].(string)
+		var value string
+		switch v := params[i+1].(type) {
+		case int:
+			value = strconv.Itoa(v)
+		case string:
+			value = v
+		}
+		text = strings.ReplaceAll(text, "{"+name+"}", value)
+	}
+	return text
+}
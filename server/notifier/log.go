@@ -0,0 +1,27 @@
+package notifier
+
+import (
+	"context"
+	"log"
+
+	"tally/models"
+)
+
+// logNotifier 将通知写入服务日志，无需额外配置，便于验证调度是否工作
+type logNotifier struct {
+	name string
+}
+
+func init() {
+	Register("log", func(channel *models.NotificationChannel) (Notifier, error) {
+		return &logNotifier{name: channel.Name}, nil
+	})
+}
+
+func (n *logNotifier) Send(ctx context.Context, msg Message) error {
+	for _, r := range msg.Resources {
+		log.Printf("[notify:%s] %s: %s (group: %s) remaining %d days",
+			n.name, msg.Event, r.Name, r.GroupName, r.RemainingDays)
+	}
+	return nil
+}
@@ -0,0 +1,133 @@
+package notifier
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"sync"
+
+	"tally/models"
+)
+
+// Event 通知事件类型
+type Event string
+
+const (
+	EventReminder   Event = "reminder"         // 资源到达提醒阈值
+	EventTest       Event = "test"             // 测试消息
+	EventCreated    Event = "resource.created" // 资源创建
+	EventRenewed    Event = "resource.renewed" // 资源续约
+	EventDeleted    Event = "resource.deleted" // 资源删除
+	EventDigest     Event = "digest"           // 定期到期摘要
+	EventEscalation Event = "escalation"       // 未确认提醒的升级通知
+
+	// EventAutoRenewed 自动续费的资源到期后按计费周期推进了到期时间
+	EventAutoRenewed Event = "resource.auto_renewed"
+)
+
+// Message 一次通知的内容，Resources 按到期时间升序排列
+type Message struct {
+	Event     Event
+	Language  string // 消息语言（zh / en），为空时使用渠道配置的语言
+	Resources []models.ResourceResponse
+	Digest    *Digest // 仅摘要消息使用，Resources 为摘要中的全部资源
+	// 仅升级通知使用，表示当前为升级链的第几级（从 1 开始）及总级数
+	EscalationStep  int
+	EscalationSteps int
+}
+
+// Notifier 通知渠道接口，新增渠道只需实现该接口并在 init 中注册
+type Notifier interface {
+	Send(ctx context.Context, msg Message) error
+}
+
+// EventFilter 可选接口，实现该接口的渠道可以订阅资源变更事件或过滤提醒
+// 未实现该接口的渠道只接收提醒与测试消息
+type EventFilter interface {
+	Accepts(event Event) bool
+}
+
+// ResourceSender 可选接口，实现该接口的渠道按资源逐条投递，自行为每次投递设置超时，
+// 部分资源失败时 Send 返回 *PartialError
+type ResourceSender interface {
+	SendsPerResource()
+}
+
+// PartialError 按资源逐条投递时部分资源发送失败，调用方只应重试 Failed 中的资源
+type PartialError struct {
+	Failed map[uint]error // 资源 ID → 错误
+}
+
+func (e *PartialError) Error() string {
+	ids := make([]uint, 0, len(e.Failed))
+	for id := range e.Failed {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+
+	msg := fmt.Sprintf("%d resource(s) failed", len(ids))
+	for _, id := range ids {
+		msg += fmt.Sprintf("; resource %d: %v", id, e.Failed[id])
+	}
+	return msg
+}
+
+// Unwrap 返回各资源的错误，便于 errors.Is 判断失败原因
+func (e *PartialError) Unwrap() []error {
+	errs := make([]error, 0, len(e.Failed))
+	for _, err := range e.Failed {
+		errs = append(errs, err)
+	}
+	return errs
+}
+
+// Factory 根据渠道配置创建 Notifier，配置非法时返回错误
+type Factory func(channel *models.NotificationChannel) (Notifier, error)
+
+var (
+	mu        sync.RWMutex
+	factories = map[string]Factory{}
+)
+
+// Register 注册渠道类型
+func Register(channelType string, factory Factory) {
+	mu.Lock()
+	defer mu.Unlock()
+	factories[channelType] = factory
+}
+
+// Types 返回所有已注册的渠道类型
+func Types() []string {
+	mu.RLock()
+	defer mu.RUnlock()
+
+	types := make([]string, 0, len(factories))
+	for t := range factories {
+		types = append(types, t)
+	}
+	sort.Strings(types)
+	return types
+}
+
+// New 根据渠道记录创建对应的 Notifier
+func New(channel *models.NotificationChannel) (Notifier, error) {
+	mu.RLock()
+	factory, ok := factories[channel.Type]
+	mu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("unknown channel type: %s", channel.Type)
+	}
+	return factory(channel)
+}
+
+// decodeConfig 解析渠道 JSON 配置，空配置视为 {}
+func decodeConfig(channel *models.NotificationChannel, v interface{}) error {
+	if channel.Config == "" {
+		return nil
+	}
+	if err := json.Unmarshal([]byte(channel.Config), v); err != nil {
+		return fmt.Errorf("invalid %s config: %w", channel.Type, err)
+	}
+	return nil
+}
@@ -0,0 +1,81 @@
+package notifier
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"tally/models"
+)
+
+// ntfyConfig ntfy 推送配置
+type ntfyConfig struct {
+	Server   string `json:"server"` // 默认 https://ntfy.sh
+	Topic    string `json:"topic"`
+	Token    string `json:"token"` // Access token，与用户名密码二选一
+	Username string `json:"username"`
+	Password string `json:"password"`
+}
+
+type ntfyNotifier struct {
+	config ntfyConfig
+}
+
+// ntfyPriorities ntfy 优先级 1~5
+var ntfyPriorities = map[Priority]int{
+	PriorityLow:     2,
+	PriorityDefault: 3,
+	PriorityHigh:    4,
+	PriorityUrgent:  5,
+}
+
+func init() {
+	Register("ntfy", func(channel *models.NotificationChannel) (Notifier, error) {
+		var cfg ntfyConfig
+		if err := decodeConfig(channel, &cfg); err != nil {
+			return nil, err
+		}
+		if cfg.Server == "" {
+			cfg.Server = "https://ntfy.sh"
+		}
+		cfg.Server = strings.TrimRight(cfg.Server, "/")
+		if err := validateURL(cfg.Server); err != nil {
+			return nil, fmt.Errorf("ntfy: server %w", err)
+		}
+		if cfg.Topic == "" {
+			return nil, errors.New("ntfy: topic is required")
+		}
+		return &ntfyNotifier{config: cfg}, nil
+	})
+}
+
+func (n *ntfyNotifier) Send(ctx context.Context, msg Message) error {
+	priority := priorityOf(msg)
+	tags := []string{"calendar"}
+	if priority == PriorityUrgent {
+		tags = []string{"rotating_light"}
+	}
+
+	// 使用 JSON 发布方式，避免标题中的非 ASCII 字符放在请求头里
+	payload := map[string]interface{}{
+		"topic":    n.config.Topic,
+		"title":    Title(msg),
+		"message":  Markdown(msg),
+		"markdown": true,
+		"priority": ntfyPriorities[priority],
+		"tags":     tags,
+	}
+
+	headers := map[string]string{}
+	if n.config.Token != "" {
+		headers["Authorization"] = "Bearer " + n.config.Token
+	} else if n.config.Username != "" {
+		headers["Authorization"] = basicAuth(n.config.Username, n.config.Password)
+	}
+
+	if _, err := postJSON(ctx, n.config.Server, payload, headers); err != nil {
+		return fmt.Errorf("ntfy: %w", err)
+	}
+	return nil
+}
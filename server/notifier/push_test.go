@@ -0,0 +1,233 @@
+package notifier
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"tally/models"
+)
+
+// capturedRequest 本地 HTTP 替身收到的请求
+type capturedRequest struct {
+	method string
+	path   string
+	header http.Header
+	body   []byte
+}
+
+// startPushServer 启动本地 HTTP 替身，记录收到的请求并以 status / response 应答
+func startPushServer(t *testing.T, status int, response string) (*httptest.Server, <-chan capturedRequest) {
+	t.Helper()
+	requests := make(chan capturedRequest, 1)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		requests <- capturedRequest{method: r.Method, path: r.URL.Path, header: r.Header.Clone(), body: body}
+		w.WriteHeader(status)
+		io.WriteString(w, response)
+	}))
+	t.Cleanup(ts.Close)
+	return ts, requests
+}
+
+func newChannel(t *testing.T, channelType string, cfg map[string]interface{}) Notifier {
+	t.Helper()
+	b, _ := json.Marshal(cfg)
+	n, err := New(&models.NotificationChannel{Type: channelType, Config: string(b)})
+	if err != nil {
+		t.Fatalf("New(%s): %v", channelType, err)
+	}
+	return n
+}
+
+// messageWithin 构造最近到期资源剩余 days 天的提醒消息
+func messageWithin(days int) Message {
+	return Message{
+		Event:     EventReminder,
+		Language:  "en",
+		Resources: []models.ResourceResponse{{ID: 1, Name: "example.com", RemainingDays: days}},
+	}
+}
+
+func decodeJSONBody(t *testing.T, req capturedRequest) map[string]interface{} {
+	t.Helper()
+	var payload map[string]interface{}
+	if err := json.Unmarshal(req.body, &payload); err != nil {
+		t.Fatalf("invalid JSON body %q: %v", req.body, err)
+	}
+	return payload
+}
+
+func TestNtfySend(t *testing.T) {
+	ts, requests := startPushServer(t, http.StatusOK, `{}`)
+	n := newChannel(t, "ntfy", map[string]interface{}{"server": ts.URL + "/", "topic": "renewals", "token": "tk_123"})
+
+	msg := messageWithin(0)
+	if err := n.Send(context.Background(), msg); err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+	req := <-requests
+	if req.method != http.MethodPost || req.path != "/" {
+		t.Errorf("request = %s %s", req.method, req.path)
+	}
+	if got := req.header.Get("Authorization"); got != "Bearer tk_123" {
+		t.Errorf("Authorization = %q", got)
+	}
+	payload := decodeJSONBody(t, req)
+	if payload["topic"] != "renewals" || payload["title"] != Title(msg) || payload["markdown"] != true {
+		t.Errorf("payload = %v", payload)
+	}
+	if payload["priority"] != float64(5) {
+		t.Errorf("priority = %v, want 5", payload["priority"])
+	}
+}
+
+func TestNtfyBasicAuthAndPriorities(t *testing.T) {
+	tests := []struct {
+		days     int
+		priority float64
+	}{
+		{0, 5}, {3, 4}, {7, 3}, {30, 2},
+	}
+	for _, tt := range tests {
+		ts, requests := startPushServer(t, http.StatusOK, `{}`)
+		n := newChannel(t, "ntfy", map[string]interface{}{"server": ts.URL, "topic": "t", "username": "alice", "password": "pw"})
+		if err := n.Send(context.Background(), messageWithin(tt.days)); err != nil {
+			t.Fatalf("Send: %v", err)
+		}
+		req := <-requests
+		if got := req.header.Get("Authorization"); got != basicAuth("alice", "pw") {
+			t.Errorf("Authorization = %q", got)
+		}
+		if got := decodeJSONBody(t, req)["priority"]; got != tt.priority {
+			t.Errorf("days %d: priority = %v, want %v", tt.days, got, tt.priority)
+		}
+	}
+}
+
+func TestGotifySend(t *testing.T) {
+	ts, requests := startPushServer(t, http.StatusOK, `{"id":1}`)
+	n := newChannel(t, "gotify", map[string]interface{}{"server": ts.URL + "/", "token": "app-token"})
+
+	if err := n.Send(context.Background(), messageWithin(3)); err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+	req := <-requests
+	if req.path != "/message" {
+		t.Errorf("path = %q", req.path)
+	}
+	if got := req.header.Get("X-Gotify-Key"); got != "app-token" {
+		t.Errorf("X-Gotify-Key = %q", got)
+	}
+	payload := decodeJSONBody(t, req)
+	if payload["priority"] != float64(7) {
+		t.Errorf("priority = %v, want 7", payload["priority"])
+	}
+	extras, _ := payload["extras"].(map[string]interface{})
+	display, _ := extras["client::display"].(map[string]interface{})
+	if display["contentType"] != "text/markdown" {
+		t.Errorf("extras = %v", payload["extras"])
+	}
+}
+
+func TestGotifyRejectedToken(t *testing.T) {
+	ts, _ := startPushServer(t, http.StatusUnauthorized, `{"error":"Unauthorized"}`)
+	n := newChannel(t, "gotify", map[string]interface{}{"server": ts.URL, "token": "wrong"})
+
+	err := n.Send(context.Background(), TestMessage())
+	if err == nil || !strings.Contains(err.Error(), "401") {
+		t.Fatalf("err = %v, want status 401", err)
+	}
+}
+
+func TestBarkSend(t *testing.T) {
+	ts, requests := startPushServer(t, http.StatusOK, `{"code":200,"message":"success"}`)
+	n := newChannel(t, "bark", map[string]interface{}{"server": ts.URL, "device_key": "dev123", "sound": "alarm"})
+
+	msg := Message{Event: EventEscalation, Language: "en", Resources: messageWithin(10).Resources, EscalationStep: 1, EscalationSteps: 2}
+	if err := n.Send(context.Background(), msg); err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+	req := <-requests
+	if req.path != "/push" {
+		t.Errorf("path = %q", req.path)
+	}
+	payload := decodeJSONBody(t, req)
+	if payload["device_key"] != "dev123" || payload["group"] != "Tally" || payload["sound"] != "alarm" {
+		t.Errorf("payload = %v", payload)
+	}
+	if payload["level"] != "critical" {
+		t.Errorf("level = %v, want critical", payload["level"])
+	}
+}
+
+func TestBarkErrorCode(t *testing.T) {
+	ts, _ := startPushServer(t, http.StatusOK, `{"code":400,"message":"failed to get device token"}`)
+	n := newChannel(t, "bark", map[string]interface{}{"server": ts.URL, "device_key": "unknown"})
+
+	err := n.Send(context.Background(), TestMessage())
+	if err == nil || !strings.Contains(err.Error(), "failed to get device token") {
+		t.Fatalf("err = %v, want bark error message", err)
+	}
+}
+
+func TestServerChanSend(t *testing.T) {
+	ts, requests := startPushServer(t, http.StatusOK, `{"code":0,"message":""}`)
+	n := newChannel(t, "serverchan", map[string]interface{}{"server": ts.URL, "send_key": "SCT123"})
+
+	msg := messageWithin(-2)
+	if err := n.Send(context.Background(), msg); err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+	req := <-requests
+	if req.path != "/SCT123.send" {
+		t.Errorf("path = %q", req.path)
+	}
+	if ct := req.header.Get("Content-Type"); ct != "application/x-www-form-urlencoded" {
+		t.Errorf("Content-Type = %q", ct)
+	}
+	form, err := url.ParseQuery(string(req.body))
+	if err != nil {
+		t.Fatalf("parse form: %v", err)
+	}
+	if form.Get("title") != "❗ "+Title(msg) {
+		t.Errorf("title = %q", form.Get("title"))
+	}
+	if !strings.Contains(form.Get("desp"), "example.com") {
+		t.Errorf("desp = %q", form.Get("desp"))
+	}
+}
+
+func TestServerChanErrorCode(t *testing.T) {
+	ts, _ := startPushServer(t, http.StatusOK, `{"code":40001,"message":"bad pushkey"}`)
+	n := newChannel(t, "serverchan", map[string]interface{}{"server": ts.URL, "send_key": "SCT123"})
+
+	err := n.Send(context.Background(), TestMessage())
+	if err == nil || !strings.Contains(err.Error(), "bad pushkey") {
+		t.Fatalf("err = %v, want serverchan error message", err)
+	}
+}
+
+func TestPushConfigValidation(t *testing.T) {
+	tests := []struct {
+		channelType string
+		cfg         string
+	}{
+		{"ntfy", `{"server":"https://ntfy.sh"}`},
+		{"ntfy", `{"server":"ftp://ntfy.sh","topic":"t"}`},
+		{"gotify", `{"server":"https://gotify.example.com"}`},
+		{"gotify", `{"token":"t"}`},
+		{"bark", `{}`},
+		{"serverchan", `{}`},
+	}
+	for _, tt := range tests {
+		if _, err := New(&models.NotificationChannel{Type: tt.channelType, Config: tt.cfg}); err == nil {
+			t.Errorf("%s %s: expected error", tt.channelType, tt.cfg)
+		}
+	}
+}
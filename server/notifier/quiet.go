@@ -0,0 +1,74 @@
+package notifier
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"tally/models"
+)
+
+// ErrQuietHours 渠道处于免打扰时段，非紧急提醒延后发送
+var ErrQuietHours = errors.New("channel is in quiet hours")
+
+// parseClock 解析 HH:MM，返回当天的分钟数
+func parseClock(s string) (int, error) {
+	t, err := time.Parse("15:04", s)
+	if err != nil {
+		return 0, fmt.Errorf("invalid time %q, expected HH:MM", s)
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
+
+// ValidateQuietHours 校验免打扰配置，起止时间需同时设置或同时为空
+func ValidateQuietHours(start, end, timezone string) error {
+	if start == "" && end == "" {
+		return nil
+	}
+	if start == "" || end == "" {
+		return errors.New("quiet_start and quiet_end must be set together")
+	}
+	if _, err := parseClock(start); err != nil {
+		return err
+	}
+	if _, err := parseClock(end); err != nil {
+		return err
+	}
+	if timezone != "" {
+		if _, err := time.LoadLocation(timezone); err != nil {
+			return fmt.Errorf("invalid quiet_timezone %q", timezone)
+		}
+	}
+	return nil
+}
+
+// InQuietHours 判断渠道在 now 时刻是否处于免打扰时段，支持跨越午夜（如 22:00~08:00）
+func InQuietHours(channel *models.NotificationChannel, now time.Time) bool {
+	if channel.QuietStart == "" || channel.QuietEnd == "" {
+		return false
+	}
+	start, err1 := parseClock(channel.QuietStart)
+	end, err2 := parseClock(channel.QuietEnd)
+	if err1 != nil || err2 != nil || start == end {
+		return false
+	}
+
+	loc := time.Local
+	if channel.QuietTimezone != "" {
+		if l, err := time.LoadLocation(channel.QuietTimezone); err == nil {
+			loc = l
+		}
+	}
+	local := now.In(loc)
+	minute := local.Hour()*60 + local.Minute()
+
+	if start < end {
+		return minute >= start && minute < end
+	}
+	return minute >= start || minute < end
+}
+
+// IsUrgent 紧急消息（今天到期或已过期、升级提醒）不受免打扰限制
+func IsUrgent(msg Message) bool {
+	return priorityOf(msg) == PriorityUrgent
+}
@@ -0,0 +1,89 @@
+package notifier
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func TestDingtalkSign(t *testing.T) {
+	// Base64(HMAC-SHA256("SECtest", "1700000000000\nSECtest"))
+	const want = "aZLLrriXgn05YbwaGR7knYsLeJADjr9NwLaNNKpxh4g="
+	if got := dingtalkSign("SECtest", "1700000000000"); got != want {
+		t.Errorf("dingtalkSign = %s, want %s", got, want)
+	}
+}
+
+func TestFeishuSign(t *testing.T) {
+	// Base64(HMAC-SHA256(key = "1700000000\nSECtest", data = ""))
+	const want = "G7XpBpG8NgG02fJOAhX6FRAObIljmFoxVReo8I62pEk="
+	if got := feishuSign("SECtest", "1700000000"); got != want {
+		t.Errorf("feishuSign = %s, want %s", got, want)
+	}
+}
+
+func TestDingtalkSignedSend(t *testing.T) {
+	queries := make(chan url.Values, 1)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		queries <- r.URL.Query()
+		io.WriteString(w, `{"errcode":0,"errmsg":"ok"}`)
+	}))
+	defer ts.Close()
+
+	n := newChannel(t, "dingtalk", map[string]interface{}{"url": ts.URL + "/robot/send?access_token=abc", "secret": "SECtest"})
+	if err := n.Send(context.Background(), TestMessage()); err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+
+	query := <-queries
+	if query.Get("access_token") != "abc" {
+		t.Errorf("access_token = %q", query.Get("access_token"))
+	}
+	timestamp := query.Get("timestamp")
+	if timestamp == "" || query.Get("sign") != dingtalkSign("SECtest", timestamp) {
+		t.Errorf("timestamp = %q, sign = %q", timestamp, query.Get("sign"))
+	}
+}
+
+func TestFeishuSignedSend(t *testing.T) {
+	ts, requests := startPushServer(t, http.StatusOK, `{"code":0,"msg":"success"}`)
+	n := newChannel(t, "feishu", map[string]interface{}{"url": ts.URL, "secret": "SECtest"})
+	if err := n.Send(context.Background(), messageWithin(0)); err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+
+	payload := decodeJSONBody(t, <-requests)
+	timestamp, _ := payload["timestamp"].(string)
+	if timestamp == "" || payload["sign"] != feishuSign("SECtest", timestamp) {
+		t.Errorf("timestamp = %v, sign = %v", payload["timestamp"], payload["sign"])
+	}
+	card, _ := payload["card"].(map[string]interface{})
+	header, _ := card["header"].(map[string]interface{})
+	if header["template"] != "red" {
+		t.Errorf("card template = %v, want red", header["template"])
+	}
+}
+
+func TestRobotErrorCodes(t *testing.T) {
+	tests := []struct {
+		channelType string
+		response    string
+		want        string
+	}{
+		{"dingtalk", `{"errcode":310000,"errmsg":"sign not match"}`, "errcode 310000: sign not match"},
+		{"wecom", `{"errcode":93000,"errmsg":"invalid webhook url"}`, "errcode 93000: invalid webhook url"},
+		{"feishu", `{"code":19021,"msg":"sign match fail"}`, "code 19021: sign match fail"},
+	}
+	for _, tt := range tests {
+		ts, _ := startPushServer(t, http.StatusOK, tt.response)
+		n := newChannel(t, tt.channelType, map[string]interface{}{"url": ts.URL})
+		err := n.Send(context.Background(), TestMessage())
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("%s: err = %v, want %q", tt.channelType, err, tt.want)
+		}
+	}
+}
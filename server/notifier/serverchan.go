@@ -0,0 +1,94 @@
+package notifier
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"tally/models"
+)
+
+// serverchanConfig Server 酱推送配置
+type serverchanConfig struct {
+	SendKey string `json:"send_key"`
+	Server  string `json:"server"` // 为空时根据 SendKey 自动选择 Turbo 版或 Server 酱³ 地址
+}
+
+type serverchanNotifier struct {
+	endpoint string
+}
+
+// serverchan3Key Server 酱³ 的 SendKey 形如 sctp{uid}t...
+var serverchan3Key = regexp.MustCompile(`^sctp(\d+)t`)
+
+func init() {
+	Register("serverchan", func(channel *models.NotificationChannel) (Notifier, error) {
+		var cfg serverchanConfig
+		if err := decodeConfig(channel, &cfg); err != nil {
+			return nil, err
+		}
+		if cfg.SendKey == "" {
+			return nil, errors.New("serverchan: send_key is required")
+		}
+
+		server := strings.TrimRight(cfg.Server, "/")
+		if server == "" {
+			if m := serverchan3Key.FindStringSubmatch(cfg.SendKey); m != nil {
+				server = "https://" + m[1] + ".push.ft07.com/send"
+			} else {
+				server = "https://sctapi.ftqq.com"
+			}
+		}
+		if err := validateURL(server); err != nil {
+			return nil, fmt.Errorf("serverchan: server %w", err)
+		}
+		return &serverchanNotifier{endpoint: server + "/" + url.PathEscape(cfg.SendKey) + ".send"}, nil
+	})
+}
+
+// Send Server 酱不支持优先级，紧急程度体现在标题中
+func (n *serverchanNotifier) Send(ctx context.Context, msg Message) error {
+	title := Title(msg)
+	if priorityOf(msg) == PriorityUrgent {
+		title = "❗ " + title
+	}
+	form := url.Values{
+		"title": {title},
+		"desp":  {Markdown(msg)},
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, n.endpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("serverchan: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 64*1024))
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("serverchan: unexpected status %d", resp.StatusCode)
+	}
+
+	var result struct {
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+	}
+	if err := json.Unmarshal(body, &result); err != nil {
+		return fmt.Errorf("serverchan: invalid response: %w", err)
+	}
+	if result.Code != 0 {
+		return fmt.Errorf("serverchan: code %d: %s", result.Code, result.Message)
+	}
+	return nil
+}
@@ -0,0 +1,279 @@
+package notifier
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+
+	"tally/database"
+	"tally/models"
+)
+
+const (
+	webhookDefaultRetries = 3
+	webhookMaxRetries     = 10
+	webhookBaseBackoff    = time.Second
+	webhookMaxBackoff     = 30 * time.Second
+	webhookAttemptTimeout = 15 * time.Second
+	webhookMaxLogBody     = 4096
+)
+
+// webhookConfig Webhook 渠道配置
+type webhookConfig struct {
+	URL        string            `json:"url"`
+	Method     string            `json:"method"`   // 默认 POST
+	Headers    map[string]string `json:"headers"`  // 自定义请求头
+	Template   string            `json:"template"` // Go text/template 请求体模板，为空时使用默认 JSON
+	Secret     string            `json:"secret"`   // 非空时使用 HMAC-SHA256 签名
+	Events     []string          `json:"events"`   // 订阅的事件，为空表示全部
+	MaxRetries *int              `json:"max_retries"`
+}
+
+// WebhookPayload 默认请求体，同时作为自定义模板的数据
+type WebhookPayload struct {
+	Event         Event  `json:"event"`
+	ResourceID    uint   `json:"resource_id"`
+	Name          string `json:"name"`
+	Group         string `json:"group"`
+	ExpireAt      int64  `json:"expire_at"`
+	RemainingDays int    `json:"remaining_days"`
+	Timestamp     int64  `json:"timestamp"`
+}
+
+type webhookNotifier struct {
+	channelID uint
+	config    webhookConfig
+	retries   int
+	tmpl      *template.Template
+	client    *http.Client
+}
+
+var webhookFuncs = template.FuncMap{
+	// json 将值编码为 JSON 字面量，便于在模板中安全地拼接字符串
+	"json": func(v interface{}) (string, error) {
+		b, err := json.Marshal(v)
+		return string(b), err
+	},
+	// date 将 Unix 时间戳格式化为日期
+	"date": func(ts int64) string {
+		return time.Unix(ts, 0).Format("2006-01-02")
+	},
+}
+
+func init() {
+	Register("webhook", newWebhookNotifier)
+}
+
+func newWebhookNotifier(channel *models.NotificationChannel) (Notifier, error) {
+	var cfg webhookConfig
+	if err := decodeConfig(channel, &cfg); err != nil {
+		return nil, err
+	}
+
+	if err := validateURL(cfg.URL); err != nil {
+		return nil, fmt.Errorf("webhook: %w", err)
+	}
+	if cfg.Method == "" {
+		cfg.Method = http.MethodPost
+	}
+	cfg.Method = strings.ToUpper(cfg.Method)
+	switch cfg.Method {
+	case http.MethodPost, http.MethodPut, http.MethodPatch:
+	default:
+		return nil, fmt.Errorf("webhook: unsupported method %s", cfg.Method)
+	}
+
+	n := &webhookNotifier{
+		channelID: channel.ID,
+		config:    cfg,
+		retries:   webhookDefaultRetries,
+		client:    &http.Client{Timeout: webhookAttemptTimeout, Transport: transport},
+	}
+	if cfg.MaxRetries != nil {
+		if *cfg.MaxRetries < 0 || *cfg.MaxRetries > webhookMaxRetries {
+			return nil, fmt.Errorf("webhook: max_retries must be between 0 and %d", webhookMaxRetries)
+		}
+		n.retries = *cfg.MaxRetries
+	}
+	if cfg.Template != "" {
+		tmpl, err := template.New("webhook").Funcs(webhookFuncs).Option("missingkey=error").Parse(cfg.Template)
+		if err != nil {
+			return nil, fmt.Errorf("webhook: invalid template: %w", err)
+		}
+		n.tmpl = tmpl
+	}
+
+	return n, nil
+}
+
+// Accepts 实现 EventFilter，未配置 events 时接收全部事件
+func (n *webhookNotifier) Accepts(event Event) bool {
+	if len(n.config.Events) == 0 {
+		return true
+	}
+	for _, e := range n.config.Events {
+		if Event(e) == event {
+			return true
+		}
+	}
+	return false
+}
+
+// SendsPerResource 实现 ResourceSender
+func (n *webhookNotifier) SendsPerResource() {}
+
+// Send 每个资源单独发送一次请求，每个资源的投递（含重试）最多 SendTimeout，部分失败时返回 *PartialError
+func (n *webhookNotifier) Send(ctx context.Context, msg Message) error {
+	failed := map[uint]error{}
+	for _, r := range msg.Resources {
+		payload := WebhookPayload{
+			Event:         msg.Event,
+			ResourceID:    r.ID,
+			Name:          r.Name,
+			Group:         r.GroupName,
+			ExpireAt:      r.ExpireAt,
+			RemainingDays: r.RemainingDays,
+			Timestamp:     time.Now().Unix(),
+		}
+		deliverCtx, cancel := context.WithTimeout(ctx, SendTimeout)
+		err := n.deliver(deliverCtx, payload)
+		cancel()
+		if err != nil {
+			failed[r.ID] = err
+		}
+	}
+	if len(failed) > 0 {
+		return &PartialError{Failed: failed}
+	}
+	return nil
+}
+
+// deliver 发送单个请求，失败时按指数退避重试（1s、2s、4s...，最长 30s）
+func (n *webhookNotifier) deliver(ctx context.Context, payload WebhookPayload) error {
+	body, err := n.render(payload)
+	if err != nil {
+		return err
+	}
+
+	var lastErr error
+	for attempt := 0; attempt <= n.retries; attempt++ {
+		if attempt > 0 {
+			backoff := webhookBaseBackoff << (attempt - 1)
+			if backoff > webhookMaxBackoff {
+				backoff = webhookMaxBackoff
+			}
+			select {
+			case <-ctx.Done():
+				return fmt.Errorf("webhook: %w (last error: %v)", ctx.Err(), lastErr)
+			case <-time.After(backoff):
+			}
+		}
+
+		lastErr = n.attempt(ctx, payload, body, attempt+1)
+		if lastErr == nil {
+			return nil
+		}
+	}
+	return lastErr
+}
+
+// attempt 执行一次 HTTP 请求并写入投递记录
+func (n *webhookNotifier) attempt(ctx context.Context, payload WebhookPayload, body []byte, attempt int) error {
+	delivery := models.WebhookDelivery{
+		ChannelID:   n.channelID,
+		Event:       string(payload.Event),
+		ResourceID:  payload.ResourceID,
+		URL:         n.config.URL,
+		RequestBody: truncate(string(body), webhookMaxLogBody),
+		Attempt:     attempt,
+	}
+	start := time.Now()
+	err := n.do(ctx, body, &delivery)
+	delivery.DurationMs = time.Since(start).Milliseconds()
+	delivery.Success = err == nil
+	if err != nil {
+		delivery.Error = err.Error()
+	}
+
+	// 未保存的渠道（测试配置）不记录
+	if n.channelID != 0 {
+		if dbErr := database.DB.Create(&delivery).Error; dbErr != nil {
+			log.Printf("Webhook: failed to record delivery: %v", dbErr)
+		}
+	}
+	return err
+}
+
+func (n *webhookNotifier) do(ctx context.Context, body []byte, delivery *models.WebhookDelivery) error {
+	req, err := http.NewRequestWithContext(ctx, n.config.Method, n.config.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "Tally-Webhook")
+	req.Header.Set("X-Tally-Event", string(delivery.Event))
+	for k, v := range n.config.Headers {
+		req.Header.Set(k, v)
+	}
+	if n.config.Secret != "" {
+		timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+		req.Header.Set("X-Tally-Timestamp", timestamp)
+		req.Header.Set("X-Tally-Signature", "sha256="+SignWebhook(n.config.Secret, timestamp, body))
+	}
+
+	resp, err := n.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	respBody, _ := io.ReadAll(io.LimitReader(resp.Body, webhookMaxLogBody))
+	delivery.StatusCode = resp.StatusCode
+	delivery.ResponseBody = string(respBody)
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook: unexpected status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// render 渲染请求体，未配置模板时使用默认 JSON
+func (n *webhookNotifier) render(payload WebhookPayload) ([]byte, error) {
+	if n.tmpl == nil {
+		return json.Marshal(payload)
+	}
+
+	var buf bytes.Buffer
+	if err := n.tmpl.Execute(&buf, payload); err != nil {
+		return nil, fmt.Errorf("webhook: render template: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// SignWebhook 计算签名：hex(HMAC-SHA256(secret, timestamp + "." + body))
+// 接收方应使用 X-Tally-Timestamp 与原始请求体重新计算并比对 X-Tally-Signature
+func SignWebhook(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// truncate 截断过长的字符串，用于投递记录
+func truncate(s string, max int) string {
+	if len(s) <= max {
+		return s
+	}
+	return s[:max]
+}
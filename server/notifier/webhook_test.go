@@ -0,0 +1,130 @@
+package notifier
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"tally/models"
+)
+
+func TestSignWebhook(t *testing.T) {
+	// 与 python3 hmac.new(b"secret", b'1700000000.{"event":"test"}', hashlib.sha256) 的结果一致
+	const want = "e6a22eb66e93669c75e7a035a110d9a2ccfa7cdef62d0ecb361671b92718ee9f"
+	if got := SignWebhook("secret", "1700000000", []byte(`{"event":"test"}`)); got != want {
+		t.Errorf("SignWebhook = %s, want %s", got, want)
+	}
+	if SignWebhook("other", "1700000000", []byte(`{"event":"test"}`)) == want {
+		t.Error("signature does not depend on the secret")
+	}
+}
+
+func TestWebhookSignedDelivery(t *testing.T) {
+	requests := make(chan *http.Request, 1)
+	bodies := make(chan []byte, 1)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		requests <- r
+		bodies <- body
+	}))
+	defer ts.Close()
+
+	n := newChannel(t, "webhook", map[string]interface{}{
+		"url":      ts.URL,
+		"method":   "put",
+		"secret":   "s3cret",
+		"headers":  map[string]string{"X-Custom": "yes"},
+		"template": `{"text":{{json .Name}},"days":{{.RemainingDays}},"event":"{{.Event}}"}`,
+	})
+	msg := Message{Event: EventRenewed, Resources: []models.ResourceResponse{{ID: 7, Name: `a "quoted" name`, RemainingDays: 30}}}
+	if err := n.Send(context.Background(), msg); err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+
+	req, body := <-requests, <-bodies
+	if req.Method != http.MethodPut {
+		t.Errorf("method = %s", req.Method)
+	}
+	if req.Header.Get("X-Tally-Event") != string(EventRenewed) || req.Header.Get("X-Custom") != "yes" {
+		t.Errorf("headers = %v", req.Header)
+	}
+	timestamp := req.Header.Get("X-Tally-Timestamp")
+	if got, want := req.Header.Get("X-Tally-Signature"), "sha256="+SignWebhook("s3cret", timestamp, body); got != want {
+		t.Errorf("signature = %s, want %s", got, want)
+	}
+
+	var payload map[string]interface{}
+	if err := json.Unmarshal(body, &payload); err != nil {
+		t.Fatalf("rendered template is not JSON: %s", body)
+	}
+	if payload["text"] != `a "quoted" name` || payload["days"] != float64(30) || payload["event"] != string(EventRenewed) {
+		t.Errorf("payload = %v", payload)
+	}
+}
+
+func TestWebhookPartialFailure(t *testing.T) {
+	var mu sync.Mutex
+	attempts := map[uint]int{}
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var payload WebhookPayload
+		json.NewDecoder(r.Body).Decode(&payload)
+		mu.Lock()
+		attempts[payload.ResourceID]++
+		mu.Unlock()
+		if payload.ResourceID == 2 {
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}))
+	defer ts.Close()
+
+	n := newChannel(t, "webhook", map[string]interface{}{"url": ts.URL, "max_retries": 0})
+	msg := Message{Event: EventReminder, Resources: []models.ResourceResponse{{ID: 1}, {ID: 2}, {ID: 3}}}
+
+	err := n.Send(context.Background(), msg)
+	var partial *PartialError
+	if !errors.As(err, &partial) {
+		t.Fatalf("err = %v, want *PartialError", err)
+	}
+	if len(partial.Failed) != 1 || partial.Failed[2] == nil {
+		t.Errorf("failed = %v, want only resource 2", partial.Failed)
+	}
+	if !strings.Contains(err.Error(), "resource 2: webhook: unexpected status 500") {
+		t.Errorf("error = %q", err.Error())
+	}
+	for _, id := range []uint{1, 2, 3} {
+		if attempts[id] != 1 {
+			t.Errorf("resource %d delivered %d times, want 1", id, attempts[id])
+		}
+	}
+}
+
+func TestWebhookAccepts(t *testing.T) {
+	all := newChannel(t, "webhook", map[string]interface{}{"url": "https://example.com/hook"}).(EventFilter)
+	if !all.Accepts(EventDeleted) {
+		t.Error("webhook without events should accept every event")
+	}
+
+	filtered := newChannel(t, "webhook", map[string]interface{}{"url": "https://example.com/hook", "events": []string{"reminder"}}).(EventFilter)
+	if !filtered.Accepts(EventReminder) || filtered.Accepts(EventCreated) {
+		t.Error("webhook should only accept subscribed events")
+	}
+}
+
+func TestWebhookConfigValidation(t *testing.T) {
+	for _, cfg := range []string{
+		`{"url":"not a url"}`,
+		`{"url":"https://example.com","method":"GET"}`,
+		`{"url":"https://example.com","max_retries":-1}`,
+		`{"url":"https://example.com","template":"{{.Name"}`,
+	} {
+		if _, err := New(&models.NotificationChannel{Type: "webhook", Config: cfg}); err == nil {
+			t.Errorf("%s: expected error", cfg)
+		}
+	}
+}
@@ -0,0 +1,82 @@
+package notifier
+
+import (
+	"context"
+	"fmt"
+
+	"tally/models"
+)
+
+// wecomMaxContent 企业微信 markdown 内容的最大字节数
+const wecomMaxContent = 4096
+
+// wecomConfig 企业微信群机器人配置
+type wecomConfig struct {
+	URL string `json:"url"` // 机器人 Webhook 地址（含 key）
+}
+
+type wecomNotifier struct {
+	config wecomConfig
+}
+
+func init() {
+	Register("wecom", func(channel *models.NotificationChannel) (Notifier, error) {
+		var cfg wecomConfig
+		if err := decodeConfig(channel, &cfg); err != nil {
+			return nil, err
+		}
+		if err := validateURL(cfg.URL); err != nil {
+			return nil, fmt.Errorf("wecom: %w", err)
+		}
+		return &wecomNotifier{config: cfg}, nil
+	})
+}
+
+func (n *wecomNotifier) Send(ctx context.Context, msg Message) error {
+	content := "### " + Title(msg) + "\n\n" + wecomMarkdown(msg)
+
+	payload := map[string]interface{}{
+		"msgtype": "markdown",
+		"markdown": map[string]string{
+			"content": truncateUTF8(content, wecomMaxContent),
+		},
+	}
+
+	body, err := postJSON(ctx, n.config.URL, payload, nil)
+	if err != nil {
+		return fmt.Errorf("wecom: %w", err)
+	}
+	return checkErrcode("wecom", body)
+}
+
+// wecomMarkdown 企业微信 markdown 正文，剩余天数使用内置的字体颜色高亮
+func wecomMarkdown(msg Message) string {
+	if msg.Digest != nil {
+		return Markdown(msg)
+	}
+
+	content := intro(msg) + "\n"
+	for _, r := range sortedResources(msg.Resources) {
+		color := "info"
+		switch {
+		case r.RemainingDays <= 0:
+			color = "warning"
+		case r.RemainingDays <= 7:
+			color = "comment"
+		}
+		content += fmt.Sprintf("> **%s** [%s] %s <font color=\"%s\">%s</font>\n",
+			r.Name, groupText(msg.Language, r), dateText(r), color, remainingText(msg.Language, r.RemainingDays))
+	}
+	return content
+}
+
+// truncateUTF8 按字节截断字符串且不破坏 UTF-8 字符
+func truncateUTF8(s string, max int) string {
+	if len(s) <= max {
+		return s
+	}
+	for max > 0 && (s[max]&0xC0) == 0x80 {
+		max--
+	}
+	return s[:max]
+}
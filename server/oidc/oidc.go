@@ -0,0 +1,268 @@
+// Package oidc 实现 OpenID Connect 授权码 + PKCE 登录所需的客户端逻辑（发现、换取 token、校验 ID Token）
+package oidc
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+var httpClient = &http.Client{Timeout: 10 * time.Second}
+
+// Config 客户端配置
+type Config struct {
+	Issuer       string
+	ClientID     string
+	ClientSecret string // 公共客户端可为空，仅依赖 PKCE
+	RedirectURL  string
+	Scopes       []string
+}
+
+// Provider 发现文档中用到的端点
+type Provider struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	JWKSURI               string `json:"jwks_uri"`
+}
+
+// Client OIDC 客户端，缓存发现文档和签名公钥
+type Client struct {
+	config Config
+
+	mu       sync.Mutex
+	provider *Provider
+	keys     map[string]*rsa.PublicKey
+}
+
+func NewClient(config Config) *Client {
+	return &Client{config: config}
+}
+
+// RandomString 生成 URL 安全的随机字符串，用于 state、nonce 和 PKCE code_verifier
+func RandomString() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// CodeChallenge 按 S256 方法计算 PKCE code_challenge
+func CodeChallenge(verifier string) string {
+	sum := sha256.Sum256([]byte(verifier))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+// Discover 获取并缓存发现文档
+func (c *Client) Discover(ctx context.Context) (*Provider, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.provider != nil {
+		return c.provider, nil
+	}
+
+	wellKnown := strings.TrimSuffix(c.config.Issuer, "/") + "/.well-known/openid-configuration"
+	var provider Provider
+	if err := getJSON(ctx, wellKnown, &provider); err != nil {
+		return nil, fmt.Errorf("discovery: %w", err)
+	}
+	if provider.Issuer != c.config.Issuer {
+		return nil, fmt.Errorf("discovery: issuer mismatch %q", provider.Issuer)
+	}
+	if provider.AuthorizationEndpoint == "" || provider.TokenEndpoint == "" || provider.JWKSURI == "" {
+		return nil, errors.New("discovery: missing endpoints")
+	}
+	c.provider = &provider
+	return c.provider, nil
+}
+
+// AuthCodeURL 生成跳转到身份提供方的授权地址
+func (c *Client) AuthCodeURL(ctx context.Context, state, nonce, verifier string) (string, error) {
+	provider, err := c.Discover(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	q := url.Values{}
+	q.Set("response_type", "code")
+	q.Set("client_id", c.config.ClientID)
+	q.Set("redirect_uri", c.config.RedirectURL)
+	q.Set("scope", strings.Join(c.config.Scopes, " "))
+	q.Set("state", state)
+	q.Set("nonce", nonce)
+	q.Set("code_challenge", CodeChallenge(verifier))
+	q.Set("code_challenge_method", "S256")
+
+	sep := "?"
+	if strings.Contains(provider.AuthorizationEndpoint, "?") {
+		sep = "&"
+	}
+	return provider.AuthorizationEndpoint + sep + q.Encode(), nil
+}
+
+// Exchange 用授权码换取 ID Token，并校验签名、issuer、audience、有效期和 nonce，返回其中的声明
+func (c *Client) Exchange(ctx context.Context, code, verifier, nonce string) (jwt.MapClaims, error) {
+	provider, err := c.Discover(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	form := url.Values{}
+	form.Set("grant_type", "authorization_code")
+	form.Set("code", code)
+	form.Set("redirect_uri", c.config.RedirectURL)
+	form.Set("client_id", c.config.ClientID)
+	form.Set("code_verifier", verifier)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, provider.TokenEndpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	if c.config.ClientSecret != "" {
+		req.SetBasicAuth(url.QueryEscape(c.config.ClientID), url.QueryEscape(c.config.ClientSecret))
+	}
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("token exchange: %w", err)
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("token exchange: status %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+
+	var tokenResp struct {
+		IDToken string `json:"id_token"`
+	}
+	if err := json.Unmarshal(body, &tokenResp); err != nil || tokenResp.IDToken == "" {
+		return nil, errors.New("token exchange: missing id_token")
+	}
+
+	return c.verifyIDToken(ctx, provider, tokenResp.IDToken, nonce)
+}
+
+// verifyIDToken 校验 ID Token（仅支持 RS256 签名）
+func (c *Client) verifyIDToken(ctx context.Context, provider *Provider, raw, nonce string) (jwt.MapClaims, error) {
+	claims := jwt.MapClaims{}
+	_, err := jwt.ParseWithClaims(raw, claims, func(token *jwt.Token) (interface{}, error) {
+		kid, _ := token.Header["kid"].(string)
+		return c.publicKey(ctx, provider, kid)
+	},
+		jwt.WithValidMethods([]string{"RS256"}),
+		jwt.WithIssuer(provider.Issuer),
+		jwt.WithAudience(c.config.ClientID),
+		jwt.WithExpirationRequired(),
+		jwt.WithLeeway(time.Minute),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("id_token: %w", err)
+	}
+	if claims["nonce"] != nonce {
+		return nil, errors.New("id_token: nonce mismatch")
+	}
+	if sub, _ := claims["sub"].(string); sub == "" {
+		return nil, errors.New("id_token: missing sub")
+	}
+	return claims, nil
+}
+
+// publicKey 按 kid 查找签名公钥，找不到时重新拉取 JWKS 以支持密钥轮换
+func (c *Client) publicKey(ctx context.Context, provider *Provider, kid string) (*rsa.PublicKey, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if key := c.lookupKey(kid); key != nil {
+		return key, nil
+	}
+	keys, err := fetchKeys(ctx, provider.JWKSURI)
+	if err != nil {
+		return nil, err
+	}
+	c.keys = keys
+	if key := c.lookupKey(kid); key != nil {
+		return key, nil
+	}
+	return nil, fmt.Errorf("jwks: unknown key %q", kid)
+}
+
+// lookupKey 未指定 kid 且只有一个密钥时直接使用该密钥
+func (c *Client) lookupKey(kid string) *rsa.PublicKey {
+	if kid == "" && len(c.keys) == 1 {
+		for _, key := range c.keys {
+			return key
+		}
+	}
+	return c.keys[kid]
+}
+
+// fetchKeys 拉取 JWKS 中的 RSA 签名公钥
+func fetchKeys(ctx context.Context, jwksURI string) (map[string]*rsa.PublicKey, error) {
+	var jwks struct {
+		Keys []struct {
+			Kid string `json:"kid"`
+			Kty string `json:"kty"`
+			Use string `json:"use"`
+			N   string `json:"n"`
+			E   string `json:"e"`
+		} `json:"keys"`
+	}
+	if err := getJSON(ctx, jwksURI, &jwks); err != nil {
+		return nil, fmt.Errorf("jwks: %w", err)
+	}
+
+	keys := make(map[string]*rsa.PublicKey)
+	for _, k := range jwks.Keys {
+		if k.Kty != "RSA" || (k.Use != "" && k.Use != "sig") {
+			continue
+		}
+		n, err := base64.RawURLEncoding.DecodeString(k.N)
+		if err != nil {
+			continue
+		}
+		e, err := base64.RawURLEncoding.DecodeString(k.E)
+		if err != nil || len(e) == 0 || len(e) > 4 {
+			continue
+		}
+		keys[k.Kid] = &rsa.PublicKey{
+			N: new(big.Int).SetBytes(n),
+			E: int(new(big.Int).SetBytes(e).Int64()),
+		}
+	}
+	return keys, nil
+}
+
+func getJSON(ctx context.Context, url string, v interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("GET %s: status %d", url, resp.StatusCode)
+	}
+	return json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(v)
+}
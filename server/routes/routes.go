@@ -3,6 +3,7 @@ package routes
 import (
 	"tally/handlers"
 	"tally/middleware"
+	"tally/models"
 
 	"github.com/gin-gonic/gin"
 )
@@ -12,24 +13,129 @@ func SetupRoutes(r *gin.Engine) {
 	{
 		// 公开路由
 		api.POST("/login", handlers.Login)
+		api.POST("/login/2fa", handlers.LoginTwoFactor)
+		api.POST("/auth/refresh", handlers.RefreshToken)
+		api.GET("/auth/providers", handlers.GetAuthProviders)
+		api.GET("/auth/oidc/login", handlers.OIDCLogin)
+		api.GET("/auth/oidc/callback", handlers.OIDCCallback)
 
 		// 需要认证的路由
 		protected := api.Group("")
 		protected.Use(middleware.AuthMiddleware())
 		{
-			protected.GET("/resources", handlers.GetResources)
-			protected.POST("/resources", handlers.CreateResource)
-			protected.PUT("/resources/:id", handlers.UpdateResource)
-			protected.PATCH("/resources/:id/renew", handlers.RenewResource)
-			protected.DELETE("/resources/:id", handlers.DeleteResource)
-			protected.GET("/groups", handlers.GetGroups)
-			protected.GET("/backup", handlers.ExportBackup)
-			protected.POST("/backup/restore", handlers.ImportBackup)
+			// 工作区内的资源，通过 X-Workspace-ID 请求头选择工作区，未提供时使用个人工作区
+			scoped := protected.Group("")
+			scoped.Use(middleware.WorkspaceMiddleware())
+			setupWorkspaceRoutes(scoped)
 
 			// 用户管理
 			protected.GET("/user", handlers.GetCurrentUser)
 			protected.PUT("/user/username", handlers.UpdateUsername)
 			protected.PUT("/user/password", handlers.UpdatePassword)
+			protected.GET("/user/2fa", handlers.GetTwoFactorStatus)
+			protected.POST("/user/2fa/setup", handlers.SetupTwoFactor)
+			protected.POST("/user/2fa/enable", handlers.EnableTwoFactor)
+			protected.POST("/user/2fa/disable", handlers.DisableTwoFactor)
+			protected.POST("/user/2fa/recovery-codes", handlers.RegenerateRecoveryCodes)
+			protected.GET("/user/sessions", handlers.GetSessions)
+			protected.DELETE("/user/sessions", handlers.RevokeAllSessions)
+			protected.DELETE("/user/sessions/:id", handlers.RevokeSession)
+			protected.POST("/auth/logout", handlers.Logout)
+
+			// API Token
+			protected.GET("/tokens", handlers.GetAPITokens)
+			protected.POST("/tokens", handlers.CreateAPIToken)
+			protected.DELETE("/tokens/:id", handlers.RevokeAPIToken)
+
+			// 工作区，也可通过 /workspaces/:workspace_id 路径访问工作区内的资源
+			protected.GET("/workspaces", handlers.GetWorkspaces)
+			protected.POST("/workspaces", handlers.CreateWorkspace)
+			workspace := protected.Group("/workspaces/:workspace_id")
+			workspace.Use(middleware.WorkspaceMiddleware())
+			{
+				admin := middleware.RequireWorkspaceRole(models.WorkspaceAdmin)
+				workspace.GET("", handlers.GetWorkspace)
+				workspace.PUT("", admin, handlers.UpdateWorkspace)
+				workspace.DELETE("", admin, handlers.DeleteWorkspace)
+				workspace.GET("/members", handlers.GetWorkspaceMembers)
+				workspace.POST("/members", admin, handlers.AddWorkspaceMember)
+				workspace.PUT("/members/:user_id", admin, handlers.UpdateWorkspaceMember)
+				workspace.DELETE("/members/:user_id", handlers.RemoveWorkspaceMember)
+				setupWorkspaceRoutes(workspace)
+			}
+
+			// 通知渠道
+			protected.GET("/notifications", handlers.GetNotificationChannels)
+			protected.POST("/notifications", handlers.CreateNotificationChannel)
+			protected.GET("/notifications/types", handlers.GetNotificationTypes)
+			protected.GET("/notifications/logs", handlers.GetNotificationLogs)
+			protected.POST("/notifications/test", middleware.RequireRole(models.RoleAdmin), handlers.TestNotificationConfig)
+			protected.PUT("/notifications/:id", handlers.UpdateNotificationChannel)
+			protected.DELETE("/notifications/:id", handlers.DeleteNotificationChannel)
+			protected.POST("/notifications/:id/test", handlers.TestNotificationChannel)
+			protected.GET("/notifications/:id/deliveries", handlers.GetWebhookDeliveries)
+
+			// 到期摘要
+			protected.GET("/digest", handlers.GetDigestSetting)
+			protected.PUT("/digest", handlers.UpdateDigestSetting)
+			protected.GET("/digest/preview", handlers.PreviewDigest)
+			protected.POST("/digest/send", handlers.SendDigestNow)
+			protected.GET("/digest/history", handlers.GetDigestHistory)
+
+			// 升级策略
+			protected.GET("/escalations", handlers.GetEscalationPolicies)
+			protected.POST("/escalations", handlers.CreateEscalationPolicy)
+			protected.PUT("/escalations/:id", handlers.UpdateEscalationPolicy)
+			protected.DELETE("/escalations/:id", handlers.DeleteEscalationPolicy)
+
+			// 审计日志
+			protected.GET("/audit", handlers.GetAuditEvents)
+
+			// 汇率
+			protected.GET("/exchange-rates", handlers.GetExchangeRates)
+
+			// 用户管理（管理员）
+			admin := protected.Group("/admin")
+			admin.Use(middleware.RequireRole(models.RoleAdmin))
+			{
+				admin.GET("/users", handlers.ListUsers)
+				admin.POST("/users", handlers.CreateUser)
+				admin.PUT("/users/:id", handlers.UpdateUser)
+				admin.PUT("/users/:id/password", handlers.ResetUserPassword)
+				admin.DELETE("/users/:id/2fa", handlers.ResetUserTwoFactor)
+				admin.POST("/users/:id/unlock", handlers.UnlockUser)
+				admin.GET("/login-attempts", handlers.GetLoginAttempts)
+				admin.PUT("/exchange-rates/:currency", handlers.SetExchangeRate)
+				admin.DELETE("/exchange-rates/:currency", handlers.DeleteExchangeRate)
+				admin.DELETE("/users/:id", handlers.DeleteUser)
+			}
 		}
 	}
 }
+
+// setupWorkspaceRoutes 注册工作区内的资源路由，成员均可读取，编辑者可修改，管理员可覆盖还原
+func setupWorkspaceRoutes(g *gin.RouterGroup) {
+	editor := middleware.RequireWorkspaceRole(models.WorkspaceEditor)
+	admin := middleware.RequireWorkspaceRole(models.WorkspaceAdmin)
+
+	g.GET("/resources", handlers.GetResources)
+	g.POST("/resources", editor, handlers.CreateResource)
+	g.PUT("/resources/:id", editor, handlers.UpdateResource)
+	g.PATCH("/resources/:id/renew", editor, handlers.RenewResource)
+	g.DELETE("/resources/:id", editor, handlers.DeleteResource)
+	g.GET("/resources/:id/renewals", handlers.GetRenewals)
+	g.POST("/resources/:id/renewals/undo", editor, handlers.UndoRenewal)
+	g.GET("/resources/:id/reminder", handlers.GetReminderState)
+	g.POST("/resources/:id/ack", editor, handlers.AcknowledgeReminder)
+	g.DELETE("/resources/:id/ack", editor, handlers.UnacknowledgeReminder)
+	g.POST("/resources/:id/snooze", editor, handlers.SnoozeReminder)
+	g.DELETE("/resources/:id/snooze", editor, handlers.UnsnoozeReminder)
+	g.GET("/groups", handlers.GetGroups)
+	g.GET("/groups/settings", handlers.GetGroupSettings)
+	g.GET("/groups/settings/:name", handlers.GetGroupSetting)
+	g.PUT("/groups/settings/:name", editor, handlers.UpdateGroupSetting)
+	g.GET("/backup", handlers.ExportBackup)
+	g.POST("/backup/restore", admin, handlers.ImportBackup)
+	g.GET("/escalations/states", handlers.GetEscalationStates)
+	g.GET("/reports/spending", handlers.GetSpendingReport)
+}
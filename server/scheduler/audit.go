@@ -0,0 +1,29 @@
+package scheduler
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"tally/config"
+	"tally/database"
+	"tally/models"
+)
+
+// pruneAuditEvents 删除超过保留期限的审计事件，AUDIT_RETENTION_DAYS 为 0 时永久保留
+func pruneAuditEvents(ctx context.Context) {
+	days := config.GetAuditRetentionDays()
+	if days == 0 {
+		return
+	}
+
+	cutoff := time.Now().AddDate(0, 0, -days)
+	result := database.DB.WithContext(ctx).Where("created_at < ?", cutoff).Delete(&models.AuditEvent{})
+	if result.Error != nil {
+		log.Printf("Scheduler: failed to prune audit events: %v", result.Error)
+		return
+	}
+	if result.RowsAffected > 0 {
+		log.Printf("Scheduler: pruned %d audit events older than %d days", result.RowsAffected, days)
+	}
+}
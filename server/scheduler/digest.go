@@ -0,0 +1,179 @@
+package scheduler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+	_ "time/tzdata" // 内置时区数据，保证精简容器中也能解析 IANA 时区
+
+	"tally/database"
+	"tally/models"
+	"tally/notifier"
+)
+
+// digestRetryDelay 摘要全部发送失败后的重试间隔
+const digestRetryDelay = 15 * time.Minute
+
+var (
+	digestFailMu sync.Mutex
+	digestFailAt = map[uint]time.Time{}
+)
+
+// DigestLocation 解析摘要设置的时区，非法时回退到服务器本地时区
+func DigestLocation(tz string) *time.Location {
+	if tz == "" {
+		return time.Local
+	}
+	loc, err := time.LoadLocation(tz)
+	if err != nil {
+		return time.Local
+	}
+	return loc
+}
+
+// DigestPeriod 返回不晚于 now 的最近一个发送时间点对应的周期标识
+// 每日摘要为 2006-01-02，每周摘要为 W2006-01-02（该周发送日的日期）
+func DigestPeriod(s *models.DigestSetting, now time.Time) string {
+	now = now.In(DigestLocation(s.Timezone))
+	occurrence := time.Date(now.Year(), now.Month(), now.Day(), s.Hour, 0, 0, 0, now.Location())
+
+	if s.Frequency == notifier.DigestWeekly {
+		diff := (int(now.Weekday()) - s.Weekday + 7) % 7
+		occurrence = occurrence.AddDate(0, 0, -diff)
+		if now.Before(occurrence) {
+			occurrence = occurrence.AddDate(0, 0, -7)
+		}
+		return "W" + occurrence.Format("2006-01-02")
+	}
+
+	if now.Before(occurrence) {
+		occurrence = occurrence.AddDate(0, 0, -1)
+	}
+	return occurrence.Format("2006-01-02")
+}
+
+// checkDigests 检查所有开启摘要的用户，到达发送时间且本周期未处理时发送
+func checkDigests(ctx context.Context) {
+	var settings []models.DigestSetting
+	if err := database.DB.Where("enabled = ?", true).Find(&settings).Error; err != nil {
+		log.Printf("Scheduler: failed to load digest settings: %v", err)
+		return
+	}
+
+	now := time.Now()
+	for i := range settings {
+		setting := &settings[i]
+		period := DigestPeriod(setting, now)
+		if period == setting.LastPeriod {
+			continue
+		}
+
+		digestFailMu.Lock()
+		failedAt, failed := digestFailAt[setting.UserID]
+		digestFailMu.Unlock()
+		if failed && now.Sub(failedAt) < digestRetryDelay {
+			continue
+		}
+
+		history, err := SendDigest(ctx, setting, period)
+		if history != nil && err != nil {
+			// 部分渠道成功，记录失败的渠道后视为已发送
+			log.Printf("Scheduler: digest for user %d partially failed: %v", setting.UserID, err)
+		} else if err != nil && !errors.Is(err, ErrEmptyDigest) {
+			log.Printf("Scheduler: failed to send digest for user %d: %v", setting.UserID, err)
+			digestFailMu.Lock()
+			digestFailAt[setting.UserID] = now
+			digestFailMu.Unlock()
+			continue
+		}
+
+		digestFailMu.Lock()
+		delete(digestFailAt, setting.UserID)
+		digestFailMu.Unlock()
+
+		// 摘要为空时同样标记为已处理，本周期不再发送
+		database.DB.Model(setting).Update("last_period", period)
+	}
+}
+
+// ErrEmptyDigest 摘要中没有需要关注的资源，跳过发送
+var ErrEmptyDigest = errors.New("digest is empty")
+
+// BuildDigest 为用户生成当前的到期摘要
+func BuildDigest(setting *models.DigestSetting) (*notifier.Digest, error) {
+	var resources []models.Resource
+	if err := database.DB.
+		Where("workspace_id IN (?)", database.DB.Model(&models.WorkspaceMember{}).Select("workspace_id").Where("user_id = ?", setting.UserID)).
+		Find(&resources).Error; err != nil {
+		return nil, err
+	}
+	return notifier.BuildDigest(setting.Frequency, toResponses(resources)), nil
+}
+
+// SendDigest 生成并发送摘要，至少一个渠道发送成功时写入历史记录
+func SendDigest(ctx context.Context, setting *models.DigestSetting, period string) (*models.DigestHistory, error) {
+	digest, err := BuildDigest(setting)
+	if err != nil {
+		return nil, err
+	}
+	if digest.Count() == 0 {
+		return nil, ErrEmptyDigest
+	}
+
+	channels, err := digestChannels(setting)
+	if err != nil {
+		return nil, err
+	}
+	if len(channels) == 0 {
+		return nil, errors.New("no enabled channel for digest")
+	}
+
+	msg := notifier.Message{Event: notifier.EventDigest, Resources: digest.Resources(), Digest: digest}
+	var sent models.IntList
+	var errs []error
+	for i := range channels {
+		if err := notifier.Send(ctx, &channels[i], msg); err != nil {
+			if !errors.Is(err, notifier.ErrNotSubscribed) {
+				errs = append(errs, fmt.Errorf("channel %d: %w", channels[i].ID, err))
+			}
+			continue
+		}
+		sent = append(sent, int(channels[i].ID))
+	}
+	if len(sent) == 0 {
+		return nil, errors.Join(append(errs, errors.New("digest was not delivered to any channel"))...)
+	}
+
+	content, _ := json.Marshal(digest)
+	history := models.DigestHistory{
+		UserID:     setting.UserID,
+		Frequency:  setting.Frequency,
+		Period:     period,
+		ItemCount:  digest.Count(),
+		ChannelIDs: sent,
+		Content:    string(content),
+		SentAt:     time.Now(),
+	}
+	if err := database.DB.Create(&history).Error; err != nil {
+		log.Printf("Scheduler: failed to record digest history: %v", err)
+	}
+	return &history, errors.Join(errs...)
+}
+
+// digestChannels 摘要使用的渠道：未指定时为用户所有启用的邮件渠道
+func digestChannels(setting *models.DigestSetting) ([]models.NotificationChannel, error) {
+	query := database.DB.Where("user_id = ? AND enabled = ?", setting.UserID, true)
+	if len(setting.ChannelIDs) > 0 {
+		query = query.Where("id IN ?", []int(setting.ChannelIDs))
+	} else {
+		query = query.Where("type = ?", "email")
+	}
+
+	var channels []models.NotificationChannel
+	err := query.Find(&channels).Error
+	return channels, err
+}
@@ -0,0 +1,186 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"tally/database"
+	"tally/models"
+	"tally/notifier"
+)
+
+// checkEscalations 为进入升级窗口的资源启动升级，并推进到期未确认的升级
+func checkEscalations(ctx context.Context) {
+	var policies []models.EscalationPolicy
+	if err := database.DB.Find(&policies).Error; err != nil {
+		log.Printf("Scheduler: failed to load escalation policies: %v", err)
+		return
+	}
+	if len(policies) == 0 {
+		return
+	}
+	byID := make(map[uint]*models.EscalationPolicy, len(policies))
+	for i := range policies {
+		byID[policies[i].ID] = &policies[i]
+	}
+
+	var resources []models.Resource
+	if err := database.DB.Where("escalation_policy_id IS NOT NULL").Find(&resources).Error; err != nil {
+		log.Printf("Scheduler: failed to load resources: %v", err)
+		return
+	}
+
+	acks := currentAcks(resources)
+	now := time.Now()
+	for i := range resources {
+		r := &resources[i]
+		policy := byID[*r.EscalationPolicyID]
+		if policy == nil || len(policy.Steps) == 0 {
+			continue
+		}
+
+		state := models.EscalationState{ResourceID: r.ID, ExpireAt: r.ExpireAt.Unix()}
+		found := database.DB.Where("resource_id = ? AND expire_at = ?", state.ResourceID, state.ExpireAt).
+			Limit(1).Find(&state).RowsAffected > 0
+		ack := acks[r.ID]
+
+		if !found {
+			if r.ToResponse().RemainingDays > policy.TriggerDays || (ack != nil && ack.Silenced(now)) {
+				continue
+			}
+			next := now.Add(time.Duration(policy.Steps[0].AfterHours) * time.Hour)
+			state.PolicyID = policy.ID
+			state.Status = models.EscalationActive
+			state.NextAt = &next
+			state.StartedAt = now
+			if err := database.DB.Create(&state).Error; err != nil {
+				log.Printf("Scheduler: failed to start escalation for resource %d: %v", r.ID, err)
+				continue
+			}
+		}
+
+		if state.Status != models.EscalationActive {
+			continue
+		}
+		if ack != nil && ack.AcknowledgedAt != nil {
+			// 兜底：确认接口未能同步更新升级状态时在此补上
+			state.Status = models.EscalationAcknowledged
+			state.NextAt = nil
+			database.DB.Save(&state)
+			continue
+		}
+		// 暂停期间不升级，暂停结束后继续
+		if (ack != nil && ack.Silenced(now)) || state.NextAt == nil || state.NextAt.After(now) {
+			continue
+		}
+
+		advanceEscalation(ctx, policy, r, &state, now)
+	}
+}
+
+// advanceEscalation 通知下一级，至少一个渠道发送成功才推进，否则下次扫描重试
+func advanceEscalation(ctx context.Context, policy *models.EscalationPolicy, r *models.Resource, state *models.EscalationState, now time.Time) {
+	if state.Step >= len(policy.Steps) {
+		state.Status = models.EscalationExhausted
+		state.NextAt = nil
+		database.DB.Save(state)
+		return
+	}
+
+	step := policy.Steps[state.Step]
+	channels := stepChannels(policy, step, r)
+	msg := notifier.Message{
+		Event:           notifier.EventEscalation,
+		Resources:       []models.ResourceResponse{r.ToResponse()},
+		EscalationStep:  state.Step + 1,
+		EscalationSteps: len(policy.Steps),
+	}
+
+	sent := false
+	for i := range channels {
+		err := notifier.Send(ctx, &channels[i], msg)
+		if errors.Is(err, notifier.ErrNotSubscribed) {
+			continue
+		}
+		if err != nil {
+			log.Printf("Scheduler: failed to escalate resource %d via channel %d (%s): %v",
+				r.ID, channels[i].ID, channels[i].Type, err)
+			continue
+		}
+		sent = true
+	}
+	if !sent && len(channels) > 0 {
+		return
+	}
+	if len(channels) == 0 {
+		log.Printf("Scheduler: escalation policy %d level %d has no enabled channel, skipped", policy.ID, state.Step+1)
+	}
+
+	state.Step++
+	if state.Step >= len(policy.Steps) {
+		state.Status = models.EscalationExhausted
+		state.NextAt = nil
+	} else {
+		next := now.Add(time.Duration(policy.Steps[state.Step].AfterHours) * time.Hour)
+		state.NextAt = &next
+	}
+	if err := database.DB.Save(state).Error; err != nil {
+		log.Printf("Scheduler: failed to save escalation state %d: %v", state.ID, err)
+	}
+}
+
+// stepChannels 返回升级级别对应的启用渠道，接收人（已）不是资源所在工作区的成员时不通知
+func stepChannels(policy *models.EscalationPolicy, step models.EscalationStep, r *models.Resource) []models.NotificationChannel {
+	recipient := policy.UserID
+	if step.UserID != 0 {
+		recipient = step.UserID
+	}
+	var count int64
+	database.DB.Model(&models.WorkspaceMember{}).
+		Where("workspace_id = ? AND user_id = ?", r.WorkspaceID, recipient).Count(&count)
+	if count == 0 {
+		log.Printf("Scheduler: escalation policy %d recipient %d is not a member of workspace %d, skipped",
+			policy.ID, recipient, r.WorkspaceID)
+		return nil
+	}
+
+	var channels []models.NotificationChannel
+	query := database.DB.Where("enabled = ?", true)
+	if step.ChannelID != 0 {
+		query = query.Where("id = ? AND user_id = ?", step.ChannelID, policy.UserID)
+	} else {
+		query = query.Where("user_id = ?", step.UserID)
+	}
+	if err := query.Find(&channels).Error; err != nil {
+		log.Printf("Scheduler: failed to load escalation channels: %v", err)
+	}
+	return channels
+}
+
+// currentAcks 返回资源当前周期的确认 / 暂停记录
+func currentAcks(resources []models.Resource) map[uint]*models.ReminderAck {
+	acks := make(map[uint]*models.ReminderAck)
+	if len(resources) == 0 {
+		return acks
+	}
+
+	ids := make([]uint, len(resources))
+	expireAt := make(map[uint]int64, len(resources))
+	for i, r := range resources {
+		ids[i] = r.ID
+		expireAt[r.ID] = r.ExpireAt.Unix()
+	}
+
+	var list []models.ReminderAck
+	if err := database.DB.Where("resource_id IN ?", ids).Find(&list).Error; err != nil {
+		log.Printf("Scheduler: failed to load reminder acks: %v", err)
+	}
+	for i := range list {
+		if list[i].ExpireAt == expireAt[list[i].ResourceID] {
+			acks[list[i].ResourceID] = &list[i]
+		}
+	}
+	return acks
+}
@@ -0,0 +1,131 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"tally/audit"
+	"tally/database"
+	"tally/models"
+	"tally/notifier"
+
+	"gorm.io/gorm"
+)
+
+// errExpireAtChanged 自动续费期间资源的到期时间已被其他请求修改
+var errExpireAtChanged = errors.New("expire_at changed")
+
+// checkAutoRenewals 将已到期的自动续费资源按计费周期推进到期时间
+func checkAutoRenewals(ctx context.Context) {
+	now := time.Now()
+
+	var resources []models.Resource
+	if err := database.DB.WithContext(ctx).
+		Where("auto_renew = ? AND expire_at <= ?", true, now).
+		Find(&resources).Error; err != nil {
+		log.Printf("Scheduler: failed to load auto-renew resources: %v", err)
+		return
+	}
+
+	for i := range resources {
+		autoRenew(&resources[i], now)
+	}
+}
+
+// autoRenew 按计费周期推进到期时间直到晚于 now，记录一次续约
+//
+// 每个周期都从计费起点按日历计算（如 1 月 31 日按月续费依次为 2 月 28 日、3 月 31 日），月末日期不会逐期漂移。
+// 错过多个周期时只记录一条跨越整个区间的续约，费用按一个周期计算，避免长期过期的资源写入大量续约记录
+func autoRenew(r *models.Resource, now time.Time) {
+	before := *r
+	anchor, cycles := billingAnchor(r)
+
+	expireAt, skipped := r.ExpireAt, 0
+	for n := cycles + 1; !expireAt.After(now); n++ {
+		next, ok := models.NextBillingDate(anchor, r.BillingCycle, r.BillingDays, n)
+		if !ok {
+			return
+		}
+		expireAt = next
+		skipped++
+	}
+	renewal := models.Renewal{
+		ResourceID:  r.ID,
+		WorkspaceID: r.WorkspaceID,
+		Auto:        true,
+		GroupName:   r.GroupName,
+		OldExpireAt: r.ExpireAt,
+		NewExpireAt: expireAt,
+		DaysAdded:   models.RenewalDays(r.ExpireAt, expireAt),
+		Cost:        r.Price,
+		Currency:    r.Currency,
+	}
+
+	r.ExpireAt = expireAt
+
+	// 到期时间、续约记录、提醒状态和审计事件在同一事务中写入，到期时间被并发修改时放弃本次续费
+	if err := database.DB.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(r).Where("expire_at = ?", before.ExpireAt).Update("expire_at", expireAt)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return errExpireAtChanged
+		}
+		if err := tx.Create(&renewal).Error; err != nil {
+			return err
+		}
+
+		// 与手动续约一样进入新的提醒周期
+		if err := tx.Where("resource_id = ?", r.ID).Delete(&models.ReminderAck{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&models.EscalationState{}).
+			Where("resource_id = ? AND status = ?", r.ID, models.EscalationActive).
+			Updates(map[string]interface{}{"status": models.EscalationResolved, "next_at": nil}).Error; err != nil {
+			return err
+		}
+
+		return audit.Record(tx, audit.Actor{}, models.AuditResourceAuto, models.AuditTargetResource, r.ID, r.WorkspaceID, before, *r)
+	}); err != nil {
+		log.Printf("Scheduler: failed to auto-renew resource %d: %v", r.ID, err)
+		return
+	}
+
+	log.Printf("Scheduler: auto-renewed resource %d (%s) by %d cycle(s) until %s",
+		r.ID, r.Name, skipped, expireAt.Format(time.RFC3339))
+	notifier.DispatchEvent(notifier.EventAutoRenewed, *r)
+}
+
+// billingAnchor 沿连续的自动续费记录回溯计费起点，返回起点和当前到期时间对应的周期数
+//
+// 一条自动续费记录可能跨越多个周期，周期数按日历从起点推算。手动续约、撤销或修改计费周期后，以当前到期时间作为新的起点
+func billingAnchor(r *models.Resource) (time.Time, int) {
+	var renewals []models.Renewal
+	if err := database.DB.Where("resource_id = ? AND undone_at IS NULL", r.ID).Order("id DESC").Find(&renewals).Error; err != nil {
+		return r.ExpireAt, 0
+	}
+
+	anchor := r.ExpireAt
+	for _, renewal := range renewals {
+		if !renewal.Auto || renewal.NewExpireAt.Unix() != anchor.Unix() {
+			break
+		}
+		anchor = renewal.OldExpireAt
+	}
+	if anchor.Equal(r.ExpireAt) {
+		return anchor, 0
+	}
+
+	for n := 1; ; n++ {
+		next, ok := models.NextBillingDate(anchor, r.BillingCycle, r.BillingDays, n)
+		if !ok || next.After(r.ExpireAt) {
+			return r.ExpireAt, 0
+		}
+		if next.Unix() == r.ExpireAt.Unix() {
+			return anchor, n
+		}
+	}
+}
@@ -0,0 +1,112 @@
+package scheduler
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"tally/database"
+	"tally/models"
+)
+
+// setupDB 在临时目录中初始化数据库
+func setupDB(t *testing.T) {
+	t.Helper()
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(t.TempDir()); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.Chdir(wd) })
+
+	t.Setenv("JWT_SECRET", "test-jwt-secret")
+	t.Setenv("ADMIN_PASSWORD", "admin-password")
+	database.InitDB()
+}
+
+func createAutoRenewResource(t *testing.T, expireAt time.Time, cycle string, days int) models.Resource {
+	t.Helper()
+	price := 9.5
+	r := models.Resource{
+		Name:         "auto",
+		ExpireAt:     expireAt,
+		WorkspaceID:  1,
+		Price:        &price,
+		Currency:     "USD",
+		BillingCycle: cycle,
+		BillingDays:  days,
+		AutoRenew:    true,
+	}
+	if err := database.DB.Create(&r).Error; err != nil {
+		t.Fatalf("create resource: %v", err)
+	}
+	return r
+}
+
+// renewResource 以 now 执行一次自动续费，返回续费后的资源与全部续约记录
+func renewResource(t *testing.T, id uint, now time.Time) (models.Resource, []models.Renewal) {
+	t.Helper()
+	var r models.Resource
+	if err := database.DB.First(&r, id).Error; err != nil {
+		t.Fatal(err)
+	}
+	autoRenew(&r, now)
+
+	if err := database.DB.First(&r, id).Error; err != nil {
+		t.Fatal(err)
+	}
+	var renewals []models.Renewal
+	database.DB.Where("resource_id = ?", id).Order("id").Find(&renewals)
+	return r, renewals
+}
+
+func TestAutoRenewLongExpiredRecordsOneRenewal(t *testing.T) {
+	setupDB(t)
+
+	now := time.Date(2026, 6, 15, 12, 0, 0, 0, time.UTC)
+	start := now.AddDate(-2, 0, 0)
+	r := createAutoRenewResource(t, start, models.BillingCustom, 1)
+
+	r, renewals := renewResource(t, r.ID, now)
+	want := time.Date(2026, 6, 16, 12, 0, 0, 0, time.UTC)
+	if !r.ExpireAt.Equal(want) {
+		t.Errorf("expire_at = %s, want %s", r.ExpireAt, want)
+	}
+	if len(renewals) != 1 {
+		t.Fatalf("renewals = %d, want a single catch-up renewal", len(renewals))
+	}
+	got := renewals[0]
+	if !got.Auto || !got.OldExpireAt.Equal(start) || !got.NewExpireAt.Equal(want) {
+		t.Errorf("renewal = %s → %s (auto %v)", got.OldExpireAt, got.NewExpireAt, got.Auto)
+	}
+	if got.Cost == nil || *got.Cost != 9.5 {
+		t.Errorf("cost = %v, want one cycle (9.5)", got.Cost)
+	}
+
+	// 下一次续费只推进一个周期
+	r, renewals = renewResource(t, r.ID, want.Add(time.Hour))
+	if want = want.AddDate(0, 0, 1); !r.ExpireAt.Equal(want) || len(renewals) != 2 {
+		t.Errorf("expire_at = %s with %d renewals, want %s with 2", r.ExpireAt, len(renewals), want)
+	}
+}
+
+func TestAutoRenewCatchUpKeepsMonthEndAnchor(t *testing.T) {
+	setupDB(t)
+
+	start := time.Date(2025, 1, 31, 0, 0, 0, 0, time.UTC)
+	r := createAutoRenewResource(t, start, models.BillingMonthly, 0)
+
+	// 错过 2 月 28 日和 3 月 31 日，一次推进到 4 月 30 日
+	r, renewals := renewResource(t, r.ID, time.Date(2025, 4, 10, 0, 0, 0, 0, time.UTC))
+	if want := time.Date(2025, 4, 30, 0, 0, 0, 0, time.UTC); !r.ExpireAt.Equal(want) || len(renewals) != 1 {
+		t.Fatalf("expire_at = %s with %d renewals, want %s with 1", r.ExpireAt, len(renewals), want)
+	}
+
+	// 跨越多个周期的记录之后仍从 1 月 31 日推算，5 月续到 31 日而不是 30 日
+	r, renewals = renewResource(t, r.ID, time.Date(2025, 5, 1, 0, 0, 0, 0, time.UTC))
+	if want := time.Date(2025, 5, 31, 0, 0, 0, 0, time.UTC); !r.ExpireAt.Equal(want) || len(renewals) != 2 {
+		t.Errorf("expire_at = %s with %d renewals, want %s with 2", r.ExpireAt, len(renewals), want)
+	}
+}
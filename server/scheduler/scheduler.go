@@ -0,0 +1,260 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"log"
+	"sort"
+	"sync"
+	"time"
+
+	"tally/config"
+	"tally/database"
+	"tally/models"
+	"tally/notifier"
+)
+
+// digestCheckInterval 摘要与升级的检查间隔，保证按设置的整点准时发送
+const digestCheckInterval = time.Minute
+
+var once sync.Once
+
+// Start 启动后台调度协程，定期扫描资源并发送到期提醒
+func Start() {
+	once.Do(func() {
+		interval := config.GetNotifyInterval()
+		log.Printf("Notification scheduler started, interval: %s", interval)
+		go run(interval)
+	})
+}
+
+func run(interval time.Duration) {
+	// 启动后立即执行一次，之后按间隔执行
+	RunOnce(context.Background())
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	digestTicker := time.NewTicker(digestCheckInterval)
+	defer digestTicker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			safeRun(context.Background(), checkAutoRenewals)
+			safeRun(context.Background(), checkReminders)
+			safeRun(context.Background(), pruneAuditEvents)
+		case <-digestTicker.C:
+			safeRun(context.Background(), checkDigests)
+			safeRun(context.Background(), checkEscalations)
+		}
+	}
+}
+
+// RunOnce 执行一次完整的扫描
+func RunOnce(ctx context.Context) {
+	// 先推进自动续费的资源，避免对其发送到期提醒
+	safeRun(ctx, checkAutoRenewals)
+	safeRun(ctx, checkReminders)
+	safeRun(ctx, checkDigests)
+	safeRun(ctx, checkEscalations)
+	safeRun(ctx, pruneAuditEvents)
+}
+
+// safeRun 执行任务并捕获 panic，避免单次异常导致调度协程退出
+func safeRun(ctx context.Context, job func(context.Context)) {
+	defer func() {
+		if err := recover(); err != nil {
+			log.Printf("Scheduler panic: %v", err)
+		}
+	}()
+
+	job(ctx)
+}
+
+// checkReminders 找出跨越提醒阈值的资源，按渠道批量发送
+func checkReminders(ctx context.Context) {
+	var channels []models.NotificationChannel
+	if err := database.DB.Where("enabled = ?", true).Find(&channels).Error; err != nil {
+		log.Printf("Scheduler: failed to load channels: %v", err)
+		return
+	}
+	if len(channels) == 0 {
+		return
+	}
+
+	var resources []models.Resource
+	if err := database.DB.Find(&resources).Error; err != nil {
+		log.Printf("Scheduler: failed to load resources: %v", err)
+		return
+	}
+
+	policyOf := policyResolver()
+	silenced := silencedResources(resources)
+	workspaces := memberWorkspaces()
+	for i := range channels {
+		channel := &channels[i]
+
+		var due []models.Resource
+		var logs []models.NotificationLog
+		for _, r := range resources {
+			// 渠道只接收其所有者所在工作区的资源
+			if !workspaces[channel.UserID][r.WorkspaceID] || silenced[r.ID] {
+				continue
+			}
+			threshold, ok := policyOf(&r).Threshold(r.ToResponse().RemainingDays)
+			if !ok || alreadySent(r, channel.ID, threshold) {
+				continue
+			}
+			due = append(due, r)
+			logs = append(logs, models.NotificationLog{
+				ResourceID: r.ID,
+				ChannelID:  channel.ID,
+				Threshold:  threshold,
+				ExpireAt:   r.ExpireAt.Unix(),
+			})
+		}
+		// 免打扰时段只发送紧急提醒，其余不写记录，时段结束后的扫描中补发
+		if notifier.InQuietHours(channel, time.Now()) {
+			due, logs = urgentOnly(due, logs)
+		}
+		if len(due) == 0 {
+			continue
+		}
+
+		msg := notifier.Message{Event: notifier.EventReminder, Resources: toResponses(due)}
+		err := notifier.Send(ctx, channel, msg)
+		if errors.Is(err, notifier.ErrNotSubscribed) || errors.Is(err, notifier.ErrQuietHours) {
+			continue
+		}
+		var partial *notifier.PartialError
+		if errors.As(err, &partial) {
+			// 按资源逐条投递的渠道只为发送成功的资源写记录，失败的下次扫描时重试
+			log.Printf("Scheduler: failed to notify channel %d (%s): %v", channel.ID, channel.Type, err)
+			logs = succeededLogs(logs, partial.Failed)
+		} else if err != nil {
+			// 发送失败不写记录，下次扫描时重试
+			log.Printf("Scheduler: failed to notify channel %d (%s): %v", channel.ID, channel.Type, err)
+			continue
+		}
+		if len(logs) == 0 {
+			continue
+		}
+
+		now := time.Now()
+		for j := range logs {
+			logs[j].SentAt = now
+		}
+		if err := database.DB.Create(&logs).Error; err != nil {
+			log.Printf("Scheduler: failed to record notification logs: %v", err)
+		}
+	}
+}
+
+// succeededLogs 去掉发送失败的资源的记录
+func succeededLogs(logs []models.NotificationLog, failed map[uint]error) []models.NotificationLog {
+	var succeeded []models.NotificationLog
+	for _, l := range logs {
+		if _, ok := failed[l.ResourceID]; !ok {
+			succeeded = append(succeeded, l)
+		}
+	}
+	return succeeded
+}
+
+// urgentOnly 只保留今天到期或已过期的资源及其发送记录
+func urgentOnly(due []models.Resource, logs []models.NotificationLog) ([]models.Resource, []models.NotificationLog) {
+	var urgent []models.Resource
+	var urgentLogs []models.NotificationLog
+	for i, r := range due {
+		if r.ToResponse().RemainingDays <= 0 {
+			urgent = append(urgent, r)
+			urgentLogs = append(urgentLogs, logs[i])
+		}
+	}
+	return urgent, urgentLogs
+}
+
+// silencedResources 返回当前周期内已确认或暂停提醒的资源
+func silencedResources(resources []models.Resource) map[uint]bool {
+	var acks []models.ReminderAck
+	if err := database.DB.Find(&acks).Error; err != nil {
+		log.Printf("Scheduler: failed to load reminder acks: %v", err)
+	}
+
+	expireAt := make(map[uint]int64, len(resources))
+	for _, r := range resources {
+		expireAt[r.ID] = r.ExpireAt.Unix()
+	}
+
+	now := time.Now()
+	silenced := make(map[uint]bool)
+	for i := range acks {
+		if acks[i].ExpireAt == expireAt[acks[i].ResourceID] && acks[i].Silenced(now) {
+			silenced[acks[i].ResourceID] = true
+		}
+	}
+	return silenced
+}
+
+// policyResolver 加载分组设置，返回按 资源 → 分组 → 全局默认 计算生效策略的函数
+func policyResolver() func(r *models.Resource) models.ReminderPolicy {
+	defaults := models.ReminderPolicy{
+		Days:              config.GetReminderDays(),
+		RepeatAfterExpiry: config.GetReminderRepeatDays(),
+	}
+
+	var settings []models.GroupSetting
+	if err := database.DB.Find(&settings).Error; err != nil {
+		log.Printf("Scheduler: failed to load group settings: %v", err)
+	}
+	type groupKey struct {
+		workspaceID uint
+		name        string
+	}
+	groups := make(map[groupKey]*models.GroupSetting, len(settings))
+	for i := range settings {
+		groups[groupKey{settings[i].WorkspaceID, settings[i].Name}] = &settings[i]
+	}
+
+	return func(r *models.Resource) models.ReminderPolicy {
+		return models.ResolveReminderPolicy(r, groups[groupKey{r.WorkspaceID, r.GroupName}], defaults)
+	}
+}
+
+// memberWorkspaces 返回每个用户所属的工作区集合
+func memberWorkspaces() map[uint]map[uint]bool {
+	var members []models.WorkspaceMember
+	if err := database.DB.Find(&members).Error; err != nil {
+		log.Printf("Scheduler: failed to load workspace members: %v", err)
+	}
+
+	workspaces := make(map[uint]map[uint]bool)
+	for _, m := range members {
+		if workspaces[m.UserID] == nil {
+			workspaces[m.UserID] = make(map[uint]bool)
+		}
+		workspaces[m.UserID][m.WorkspaceID] = true
+	}
+	return workspaces
+}
+
+// alreadySent 判断该资源在当前提醒周期内是否已通过该渠道发送过该阈值
+func alreadySent(r models.Resource, channelID uint, threshold int) bool {
+	var count int64
+	database.DB.Model(&models.NotificationLog{}).
+		Where("resource_id = ? AND channel_id = ? AND threshold = ? AND expire_at = ?",
+			r.ID, channelID, threshold, r.ExpireAt.Unix()).
+		Count(&count)
+	return count > 0
+}
+
+// toResponses 转换为响应格式并按到期时间升序排列
+func toResponses(resources []models.Resource) []models.ResourceResponse {
+	responses := make([]models.ResourceResponse, len(resources))
+	for i, r := range resources {
+		responses[i] = r.ToResponse()
+	}
+	sort.Slice(responses, func(i, j int) bool {
+		return responses[i].ExpireAt < responses[j].ExpireAt
+	})
+	return responses
+}
@@ -42,4 +42,4 @@ Write-Host ""
 Write-Host "Installation complete!"
 Write-Host "Run .\tally.exe to start the server"
 Write-Host "Default port: 8080"
-Write-Host "Default credentials: admin / password"
+Write-Host "Initial admin password is printed in the server log on first start"
@@ -74,4 +74,4 @@ echo ""
 echo "Installation complete!"
 echo "Run ./tally to start the server"
 echo "Default port: 8080"
-echo "Default credentials: admin / password"
+echo "Initial admin password is printed in the server log on first start"
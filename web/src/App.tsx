@@ -1,28 +1,42 @@
 import { useState, useEffect } from 'react'
 import LoginPage from './components/LoginPage'
 import Dashboard from './components/Dashboard'
+import ChangePasswordPage from './components/ChangePasswordPage'
 import I18nProvider from './i18n/I18nProvider'
-import { getResources } from './api'
+import { getCurrentUser, getResources, setCurrentWorkspaceId, setTokens, logout } from './api'
 
 function App() {
   const [isLoggedIn, setIsLoggedIn] = useState(false)
   const [isChecking, setIsChecking] = useState(true)
+  const [loginError, setLoginError] = useState('')
+  const [mustChangePassword, setMustChangePassword] = useState(false)
 
   useEffect(() => {
     const checkAuth = async () => {
-      const token = localStorage.getItem('token')
-      if (!token) {
-        setIsChecking(false)
-        return
+      // 单点登录回调通过 URL fragment 返回 token 或错误信息
+      const params = new URLSearchParams(window.location.hash.slice(1))
+      if (params.has('token') || params.has('oidc_error')) {
+        window.history.replaceState(null, '', window.location.pathname + window.location.search)
+        if (params.get('token')) {
+          setTokens(params.get('token'), params.get('refresh_token'))
+          setCurrentWorkspaceId(null)
+        }
+        setLoginError(params.get('oidc_error') || '')
       }
-      
+
       try {
-        // 验证 token 是否有效
-        await getResources()
+        // 验证 token 是否有效；没有 token 时反向代理认证也可能直接通过
+        const user = await getCurrentUser()
+        // 必须修改密码时服务端拒绝访问资源，先进入修改密码页面
+        if (!user.must_change_password) {
+          await getResources()
+        }
+        setMustChangePassword(user.must_change_password)
         setIsLoggedIn(true)
       } catch {
-        // token 无效，清除并要求重新登录
-        localStorage.removeItem('token')
+        // token 无效或工作区已无权访问，清除并要求重新登录
+        setTokens(null)
+        setCurrentWorkspaceId(null)
       } finally {
         setIsChecking(false)
       }
@@ -31,13 +45,19 @@ function App() {
     checkAuth()
   }, [])
 
-  const handleLogin = (token: string) => {
-    localStorage.setItem('token', token)
+  const handleLogin = (token: string, refreshToken: string, mustChange: boolean) => {
+    setTokens(token, refreshToken)
+    setCurrentWorkspaceId(null)
+    setMustChangePassword(mustChange)
     setIsLoggedIn(true)
   }
 
   const handleLogout = () => {
-    localStorage.removeItem('token')
+    // 服务端撤销会话失败不影响本地登出
+    logout().catch(() => {})
+    setTokens(null)
+    setCurrentWorkspaceId(null)
+    setMustChangePassword(false)
     setIsLoggedIn(false)
   }
 
@@ -53,7 +73,9 @@ function App() {
   return (
     <I18nProvider>
       {!isLoggedIn ? (
-        <LoginPage onLogin={handleLogin} />
+        <LoginPage onLogin={handleLogin} initialError={loginError} />
+      ) : mustChangePassword ? (
+        <ChangePasswordPage onChanged={() => setMustChangePassword(false)} onLogout={handleLogout} />
       ) : (
         <Dashboard onLogout={handleLogout} />
       )}
@@ -1,4 +1,4 @@
-import { Resource, LoginResponse } from './types'
+import { Resource, LoginResponse, TwoFactorChallenge, AuthProviders, Session, Workspace } from './types'
 
 const API_BASE = '/api'
 
@@ -6,14 +6,65 @@ function getToken(): string | null {
   return localStorage.getItem('token')
 }
 
+// 保存登录凭证，token 为短期 access token，refreshToken 用于过期后换取新 token
+export function setTokens(token: string | null, refreshToken: string | null = null) {
+  if (token === null) {
+    localStorage.removeItem('token')
+    localStorage.removeItem('refresh_token')
+    return
+  }
+  localStorage.setItem('token', token)
+  if (refreshToken) {
+    localStorage.setItem('refresh_token', refreshToken)
+  }
+}
+
+// 同一时间只发起一次刷新，并发的 401 请求共用结果
+let refreshing: Promise<boolean> | null = null
+
+async function refreshAccessToken(): Promise<boolean> {
+  const refreshToken = localStorage.getItem('refresh_token')
+  if (!refreshToken) return false
+
+  const response = await fetch(`${API_BASE}/auth/refresh`, {
+    method: 'POST',
+    headers: { 'Content-Type': 'application/json' },
+    body: JSON.stringify({ refresh_token: refreshToken }),
+  }).catch(() => null)
+
+  if (!response?.ok) {
+    // 其他标签页可能已经刷新过，使用其保存的新 token
+    return localStorage.getItem('refresh_token') !== refreshToken
+  }
+  const data: LoginResponse = await response.json()
+  setTokens(data.token, data.refresh_token)
+  return true
+}
+
+// 当前工作区，未设置时服务端使用个人工作区
+export function getCurrentWorkspaceId(): string | null {
+  return localStorage.getItem('workspace_id')
+}
+
+export function setCurrentWorkspaceId(id: number | null) {
+  if (id === null) {
+    localStorage.removeItem('workspace_id')
+  } else {
+    localStorage.setItem('workspace_id', String(id))
+  }
+}
+
 async function request<T>(
   endpoint: string,
-  options: RequestInit = {}
+  options: RequestInit = {},
+  retry = true
 ): Promise<T> {
   const token = getToken()
+  const workspaceId = getCurrentWorkspaceId()
   const headers: HeadersInit = {
     'Content-Type': 'application/json',
     ...(token ? { Authorization: `Bearer ${token}` } : {}),
+    ...(workspaceId ? { 'X-Workspace-ID': workspaceId } : {}),
     ...options.headers,
   }
 
@@ -22,6 +73,18 @@ async function request<T>(
     headers,
   })
 
+  // access token 过期时用刷新令牌换取新 token 后重试一次
+  if (response.status === 401 && retry && token) {
+    if (!refreshing) {
+      refreshing = refreshAccessToken().finally(() => {
+        refreshing = null
+      })
+    }
+    if (await refreshing) {
+      return request<T>(endpoint, options, false)
+    }
+  }
+
   if (!response.ok) {
     const error = await response.json().catch(() => ({ error: 'Request failed' }))
     throw new Error(error.error || 'Request failed')
@@ -30,13 +93,32 @@ async function request<T>(
   return response.json()
 }
 
-export async function login(username: string, password: string): Promise<LoginResponse> {
-  return request<LoginResponse>('/login', {
+export async function login(
+  username: string,
+  password: string
+): Promise<LoginResponse | TwoFactorChallenge> {
+  return request<LoginResponse | TwoFactorChallenge>('/login', {
     method: 'POST',
     body: JSON.stringify({ username, password }),
   })
 }
 
+export async function getAuthProviders(): Promise<AuthProviders> {
+  return request<AuthProviders>('/auth/providers')
+}
+
+// 两步验证，code 为验证器中的 6 位验证码，也可以填写恢复码
+export async function loginTwoFactor(challengeToken: string, code: string): Promise<LoginResponse> {
+  const isTotp = /^\d{6}$/.test(code.trim())
+  return request<LoginResponse>('/login/2fa', {
+    method: 'POST',
+    body: JSON.stringify({
+      challenge_token: challengeToken,
+      ...(isTotp ? { code: code.trim() } : { recovery_code: code.trim() }),
+    }),
+  })
+}
+
 export async function getResources(): Promise<Resource[]> {
   return request<Resource[]>('/resources')
 }
@@ -54,7 +136,13 @@ export async function createResource(data: {
 
 export async function renewResource(
   id: number,
-  data: { days?: number; expire_at?: number } // expire_at 为 Unix 时间戳
+  data: {
+    days?: number
+    months?: number
+    years?: number
+    base?: 'expiry' | 'today'
+    expire_at?: number // Unix 时间戳
+  }
 ): Promise<Resource> {
   return request<Resource>(`/resources/${id}/renew`, {
     method: 'PATCH',
@@ -115,6 +203,8 @@ export async function restoreBackup(
 export interface UserInfo {
   id: number
   username: string
+  role: string
+  must_change_password: boolean
 }
 
 export async function getCurrentUser(): Promise<UserInfo> {
@@ -134,3 +224,27 @@ export async function updatePassword(oldPassword: string, newPassword: string):
     body: JSON.stringify({ old_password: oldPassword, new_password: newPassword }),
   })
 }
+
+// 登出当前会话
+export async function logout(): Promise<void> {
+  await request('/auth/logout', { method: 'POST' }, false)
+}
+
+// 登录会话
+export async function getSessions(): Promise<Session[]> {
+  return request<Session[]>('/user/sessions')
+}
+
+export async function revokeSession(id: number): Promise<void> {
+  await request(`/user/sessions/${id}`, { method: 'DELETE' })
+}
+
+// 登出所有设备，包括当前会话
+export async function revokeAllSessions(): Promise<void> {
+  await request('/user/sessions', { method: 'DELETE' })
+}
+
+// 工作区
+export async function getWorkspaces(): Promise<Workspace[]> {
+  return request<Workspace[]>('/workspaces')
+}
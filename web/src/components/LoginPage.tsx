@@ -1,16 +1,25 @@
-import { useState } from 'react'
+import { useState, useEffect } from 'react'
 import { LogIn, AlertCircle } from 'lucide-react'
-import { login } from '../api'
+import { login, loginTwoFactor, getAuthProviders } from '../api'
+import { AuthProviders } from '../types'
 
 interface LoginPageProps {
-  onLogin: (token: string) => void
+  onLogin: (token: string, refreshToken: string, mustChangePassword: boolean) => void
+  initialError?: string
 }
 
-export default function LoginPage({ onLogin }: LoginPageProps) {
+export default function LoginPage({ onLogin, initialError = '' }: LoginPageProps) {
   const [username, setUsername] = useState('')
   const [password, setPassword] = useState('')
-  const [error, setError] = useState('')
+  const [code, setCode] = useState('')
+  const [challengeToken, setChallengeToken] = useState('')
+  const [error, setError] = useState(initialError)
   const [loading, setLoading] = useState(false)
+  const [providers, setProviders] = useState<AuthProviders | null>(null)
+
+  useEffect(() => {
+    getAuthProviders().then(setProviders).catch(() => setProviders(null))
+  }, [])
 
   const handleSubmit = async (e: React.FormEvent) => {
     e.preventDefault()
@@ -18,8 +27,19 @@ export default function LoginPage({ onLogin }: LoginPageProps) {
     setLoading(true)
 
     try {
+      // 第二步：提交验证码换取正式 token
+      if (challengeToken) {
+        const response = await loginTwoFactor(challengeToken, code)
+        onLogin(response.token, response.refresh_token, response.must_change_password)
+        return
+      }
+
       const response = await login(username, password)
-      onLogin(response.token)
+      if ('two_factor_required' in response) {
+        setChallengeToken(response.challenge_token)
+        return
+      }
+      onLogin(response.token, response.refresh_token, response.must_change_password)
     } catch (err) {
       setError(err instanceof Error ? err.message : '登录失败')
     } finally {
@@ -46,47 +66,78 @@ export default function LoginPage({ onLogin }: LoginPageProps) {
             </div>
           )}
 
-          <div>
-            <label htmlFor="username" className="block text-sm font-medium text-gray-700 mb-2">
-              用户名
-            </label>
-            <input
-              id="username"
-              type="text"
-              value={username}
-              onChange={(e) => setUsername(e.target.value)}
-              className="w-full px-4 py-3 border border-gray-300 rounded-lg focus:ring-2 focus:ring-indigo-500 focus:border-transparent transition-all"
-              placeholder="请输入用户名"
-              required
-            />
-          </div>
+          {challengeToken ? (
+            <div>
+              <label htmlFor="code" className="block text-sm font-medium text-gray-700 mb-2">
+                两步验证码
+              </label>
+              <input
+                id="code"
+                type="text"
+                inputMode="numeric"
+                autoComplete="one-time-code"
+                value={code}
+                onChange={(e) => setCode(e.target.value)}
+                className="w-full px-4 py-3 border border-gray-300 rounded-lg focus:ring-2 focus:ring-indigo-500 focus:border-transparent transition-all"
+                placeholder="请输入验证器中的 6 位验证码或恢复码"
+                autoFocus
+                required
+              />
+            </div>
+          ) : (
+            <>
+              <div>
+                <label htmlFor="username" className="block text-sm font-medium text-gray-700 mb-2">
+                  用户名
+                </label>
+                <input
+                  id="username"
+                  type="text"
+                  value={username}
+                  onChange={(e) => setUsername(e.target.value)}
+                  className="w-full px-4 py-3 border border-gray-300 rounded-lg focus:ring-2 focus:ring-indigo-500 focus:border-transparent transition-all"
+                  placeholder="请输入用户名"
+                  required
+                />
+              </div>
 
-          <div>
-            <label htmlFor="password" className="block text-sm font-medium text-gray-700 mb-2">
-              密码
-            </label>
-            <input
-              id="password"
-              type="password"
-              value={password}
-              onChange={(e) => setPassword(e.target.value)}
-              className="w-full px-4 py-3 border border-gray-300 rounded-lg focus:ring-2 focus:ring-indigo-500 focus:border-transparent transition-all"
-              placeholder="请输入密码"
-              required
-            />
-          </div>
+              <div>
+                <label htmlFor="password" className="block text-sm font-medium text-gray-700 mb-2">
+                  密码
+                </label>
+                <input
+                  id="password"
+                  type="password"
+                  value={password}
+                  onChange={(e) => setPassword(e.target.value)}
+                  className="w-full px-4 py-3 border border-gray-300 rounded-lg focus:ring-2 focus:ring-indigo-500 focus:border-transparent transition-all"
+                  placeholder="请输入密码"
+                  required
+                />
+              </div>
+            </>
+          )}
 
           <button
             type="submit"
             disabled={loading}
             className="w-full py-3 px-4 bg-indigo-600 hover:bg-indigo-700 text-white font-medium rounded-lg transition-colors disabled:opacity-50 disabled:cursor-not-allowed"
           >
-            {loading ? '登录中...' : '登录'}
+            {loading ? '登录中...' : challengeToken ? '验证' : '登录'}
           </button>
         </form>
 
+        {providers?.oidc && !challengeToken && (
+          <a
+            href={providers.oidc.login_url}
+            className="mt-4 block w-full py-3 px-4 text-center border border-gray-300 hover:bg-gray-50 text-gray-700 font-medium rounded-lg transition-colors"
+          >
+            使用 {providers.oidc.name} 登录
+          </a>
+        )}
+
         <p className="mt-6 text-center text-sm text-gray-500">
-          默认账号: admin / password
+          首次启动时的管理员密码见服务端日志
         </p>
       </div>
     </div>
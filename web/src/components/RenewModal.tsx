@@ -32,9 +32,12 @@ export default function RenewModal({ resource, onClose, onSuccess }: RenewModalP
       : new Date(resource.expire_at * 1000)
     
     if (mode === 'years') {
-      // 自然年：保持月日不变，只增加年份
+      // 自然年：保持月日不变，只增加年份，2 月 29 日在平年取 2 月 28 日（与服务端一致）
       const result = new Date(baseDate)
+      result.setDate(1)
       result.setFullYear(result.getFullYear() + years)
+      const lastDay = new Date(result.getFullYear(), result.getMonth() + 1, 0).getDate()
+      result.setDate(Math.min(baseDate.getDate(), lastDay))
       return result
     }
     
@@ -51,9 +54,8 @@ export default function RenewModal({ resource, onClose, onSuccess }: RenewModalP
       if (mode === 'days') {
         await renewResource(resource.id, { days })
       } else if (mode === 'years') {
-        // 按年续期：计算出具体日期后发送
-        const timestamp = Math.floor(predictedExpireDate.getTime() / 1000)
-        await renewResource(resource.id, { expire_at: timestamp })
+        // 按年续期：由服务端按日历计算
+        await renewResource(resource.id, { years })
       } else {
         // 将日期转换为 Unix 时间戳（秒）
         const timestamp = Math.floor(new Date(expireAt).getTime() / 1000)
@@ -221,6 +221,9 @@ export default function SettingsModal({ onClose, onLogout }: SettingsModalProps)
                   required
                   minLength={6}
                 />
+                <p className="mt-1 text-xs text-gray-500">
+                  {t('passwordRevokesAccessHint')}
+                </p>
               </div>
               <button
                 type="submit"
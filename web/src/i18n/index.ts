@@ -111,6 +111,7 @@ export const translations = {
     settingsTitle: '账户设置',
     changeUsername: '修改用户名',
     changePassword: '修改密码',
+    mustChangePasswordHint: '首次登录请先修改初始密码',
     currentUsername: '当前用户名',
     newUsername: '新用户名',
     newUsernamePlaceholder: '输入新用户名',
@@ -125,6 +126,7 @@ export const translations = {
     updating: '更新中...',
     usernameUpdated: '用户名更新成功',
     passwordUpdated: '密码更新成功',
+    passwordRevokesAccessHint: '修改后其他登录会话和全部 API Token 将失效',
     passwordMismatch: '两次输入的密码不一致',
     passwordTooShort: '密码至少需要6个字符',
     updateFailed: '更新失败',
@@ -237,6 +239,7 @@ export const translations = {
     settingsTitle: 'Account Settings',
     changeUsername: 'Change Username',
     changePassword: 'Change Password',
+    mustChangePasswordHint: 'Please change the initial password before continuing',
     currentUsername: 'Current Username',
     newUsername: 'New Username',
     newUsernamePlaceholder: 'Enter new username',
@@ -251,6 +254,7 @@ export const translations = {
     updating: 'Updating...',
     usernameUpdated: 'Username updated successfully',
     passwordUpdated: 'Password updated successfully',
+    passwordRevokesAccessHint: 'Other sessions and all API tokens will be revoked',
     passwordMismatch: 'Passwords do not match',
     passwordTooShort: 'Password must be at least 6 characters',
     updateFailed: 'Update failed',
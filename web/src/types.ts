@@ -7,7 +7,42 @@ export interface Resource {
   remaining_days: number
 }
 
+export interface Workspace {
+  id: number
+  name: string
+  personal: boolean
+  role: 'viewer' | 'editor' | 'admin'
+  created_at: number // Unix 时间戳
+}
+
 export interface LoginResponse {
   token: string
+  refresh_token: string
+  expires_in: number
   username: string
+  role: string
+  must_change_password: boolean
+}
+
+// 登录会话（设备）
+export interface Session {
+  id: number
+  user_agent: string
+  ip: string
+  created_at: string
+  last_used_at: string
+  expires_at: string
+  current: boolean
+}
+
+// 可用的登录方式，未配置单点登录时 oidc 为 null
+export interface AuthProviders {
+  password: boolean
+  oidc: { name: string; login_url: string } | null
+}
+
+// 启用两步验证时登录第一步的返回
+export interface TwoFactorChallenge {
+  two_factor_required: true
+  challenge_token: string
 }